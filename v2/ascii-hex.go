@@ -0,0 +1,89 @@
+package util
+
+import "fmt"
+
+// HexCase is the letter case of ASCII hex digits.
+type HexCase int
+
+const (
+	// Upper case ``0-9A-F'' as IsHex
+	HEX_UPPER HexCase = iota
+	// Lower case ``0-9a-f''
+	HEX_LOWER
+	// Either case when decoding, upper case when encoding
+	HEX_ANY_CASE
+)
+
+// hexValue returns the value of c hex digit in hc case.
+func (hc HexCase) hexValue(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'A' && c <= 'F' && hc != HEX_LOWER:
+		return c - 'A' + 10, true
+	case c >= 'a' && c <= 'f' && hc != HEX_UPPER:
+		return c - 'a' + 10, true
+	}
+	return 0, false
+}
+
+// IndexNonAscii returns the index of the first byte of b that is not ASCII
+// printable character, 0x20 up to 0x7E, or -1 if there is none. IsAscii(b)
+// is the same as IndexNonAscii(b) < 0.
+func IndexNonAscii(b []byte) int {
+	for i, c := range b {
+		if c < 0x20 || c > 0x7E {
+			return i
+		}
+	}
+	return -1
+}
+
+// IndexNonHex returns the index of the first byte of b that is not hex digit
+// of hc case, or -1 if there is none. IndexNonHex(b, HEX_UPPER) < 0 is the
+// same as IsHex(b).
+func IndexNonHex(b []byte, hc HexCase) int {
+	for i, c := range b {
+		if _, ok := hc.hexValue(c); !ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// AppendAsciiHex appends the ASCII hex encoding of b to dst, two digits per
+// byte in hc case.
+func AppendAsciiHex(dst, b []byte, hc HexCase) []byte {
+	digits := "0123456789ABCDEF"
+	if hc == HEX_LOWER {
+		digits = "0123456789abcdef"
+	}
+	for _, c := range b {
+		dst = append(dst, digits[c>>4], digits[c&0x0F])
+	}
+	return dst
+}
+
+// EncodeAsciiHex returns the ASCII hex encoding of b in hc case, e.g.
+// ``1A2B'' for {0x1A, 0x2B} in HEX_UPPER.
+func EncodeAsciiHex(b []byte, hc HexCase) []byte {
+	return AppendAsciiHex(make([]byte, 0, len(b)*2), b, hc)
+}
+
+// DecodeAsciiHex decodes ASCII hex h of hc case. The error tells the index
+// of the first invalid byte.
+func DecodeAsciiHex(h []byte, hc HexCase) ([]byte, error) {
+	if i := IndexNonHex(h, hc); i >= 0 {
+		return nil, fmt.Errorf("invalid hex character %q at index %d", h[i], i)
+	}
+	if len(h)%2 != 0 {
+		return nil, fmt.Errorf("odd hex length: %d", len(h))
+	}
+	b := make([]byte, len(h)/2)
+	for i := range b {
+		hi, _ := hc.hexValue(h[2*i])
+		lo, _ := hc.hexValue(h[2*i+1])
+		b[i] = hi<<4 | lo
+	}
+	return b, nil
+}
@@ -0,0 +1,64 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ASCII hex", func() {
+	DescribeTable("IndexNonHex", func(s string, hc HexCase, i int) {
+		Expect(IndexNonHex([]byte(s), hc)).To(Equal(i))
+		if hc == HEX_UPPER {
+			Expect(IsHex([]byte(s))).To(Equal(i < 0))
+		}
+	},
+		Entry("upper", "09AF", HEX_UPPER, -1),
+		Entry("upper lower", "09Af", HEX_UPPER, 3),
+		Entry("lower", "09af", HEX_LOWER, -1),
+		Entry("lower upper", "0A", HEX_LOWER, 1),
+		Entry("any", "aF09", HEX_ANY_CASE, -1),
+		Entry("any invalid", "aFg9", HEX_ANY_CASE, 2),
+		Entry("empty", "", HEX_UPPER, -1),
+	)
+
+	DescribeTable("IndexNonAscii", func(s string, i int) {
+		Expect(IndexNonAscii([]byte(s))).To(Equal(i))
+		Expect(IsAscii([]byte(s))).To(Equal(i < 0))
+	},
+		Entry("printable", " ~abc", -1),
+		Entry("control", "ab\ncd", 2),
+		Entry("high", "\xffab", 0),
+		Entry("empty", "", -1),
+	)
+
+	It("should encode", func() {
+		b := []byte{0x1A, 0x2B, 0x00}
+		Expect(string(EncodeAsciiHex(b, HEX_UPPER))).To(Equal("1A2B00"))
+		Expect(string(EncodeAsciiHex(b, HEX_LOWER))).To(Equal("1a2b00"))
+		Expect(string(EncodeAsciiHex(b, HEX_ANY_CASE))).To(Equal("1A2B00"))
+		Expect(string(AppendAsciiHex([]byte(":"), b[:1], HEX_UPPER))).To(
+			Equal(":1A"))
+	})
+
+	DescribeTable("decode", func(s string, hc HexCase, b []byte) {
+		Expect(DecodeAsciiHex([]byte(s), hc)).To(Equal(b))
+	},
+		Entry("upper", "1A2B", HEX_UPPER, []byte{0x1A, 0x2B}),
+		Entry("lower", "1a2b", HEX_LOWER, []byte{0x1A, 0x2B}),
+		Entry("any", "1a2B", HEX_ANY_CASE, []byte{0x1A, 0x2B}),
+		Entry("empty", "", HEX_UPPER, []byte{}),
+	)
+
+	DescribeTable("decode error", func(s string, hc HexCase, msg string) {
+		_, err := DecodeAsciiHex([]byte(s), hc)
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("case", "1A2b", HEX_UPPER, "invalid hex character 'b' at index 3"),
+		Entry("invalid", "1:", HEX_ANY_CASE,
+			"invalid hex character ':' at index 1"),
+		Entry("odd", "1A2", HEX_UPPER, "odd hex length: 3"),
+	)
+})
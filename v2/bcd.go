@@ -0,0 +1,180 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BCD nibbles of the sign, any other nibble above 9 is also accepted by the
+// decoder as positive, except 0xB which is negative.
+const (
+	BCD_PLUS  = 0xC
+	BCD_MINUS = 0xD
+)
+
+// BCD is binary coded decimal codec. The zero value is unsigned packed BCD
+// of minimum size, e.g. 1234 is {0x12, 0x34}.
+type BCD struct {
+	// Store one digit per byte in the low nibble, instead of two digits per
+	// byte.
+	Unpacked bool
+	// Store the sign nibble, as the last nibble of packed BCD, e.g. -123 is
+	// {0x12, 0x3D}, or the high nibble of the last unpacked byte, e.g.
+	// {0x01, 0x02, 0xD3}.
+	Signed bool
+	// Size in bytes, the value is padded by leading zeroes. Zero is the
+	// minimum size.
+	Size int
+}
+
+func isBCDDigit(n byte) bool {
+	return n <= 9
+}
+
+func isBCDSign(n byte) bool {
+	return n >= 0xA && n <= 0xF
+}
+
+// IndexInvalid returns the index of the first invalid byte of b, or -1 if
+// there is none.
+func (c BCD) IndexInvalid(b []byte) int {
+	for i, x := range b {
+		hi, lo := x>>4, x&0x0F
+		last := c.Signed && i == len(b)-1
+		switch {
+		case c.Unpacked && last:
+			if !isBCDSign(hi) || !isBCDDigit(lo) {
+				return i
+			}
+		case c.Unpacked:
+			if hi != 0 || !isBCDDigit(lo) {
+				return i
+			}
+		case last:
+			if !isBCDDigit(hi) || !isBCDSign(lo) {
+				return i
+			}
+		default:
+			if !isBCDDigit(hi) || !isBCDDigit(lo) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Encode returns BCD of n, negative n requires Signed.
+func (c BCD) Encode(n int64) ([]byte, error) {
+	if n < 0 && !c.Signed {
+		return nil, fmt.Errorf("negative value: %d", n)
+	}
+	return c.EncodeDigits(strconv.FormatInt(n, 10))
+}
+
+// EncodeDigits returns BCD of s decimal digits, keeping its leading zeroes.
+// Signed BCD accepts leading ``+'' or ``-'' sign.
+func (c BCD) EncodeDigits(s string) ([]byte, error) {
+	digits := s
+	neg := false
+	if c.Signed && (strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+")) {
+		neg = s[0] == '-'
+		digits = s[1:]
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return nil, fmt.Errorf("invalid digit %q at index %d",
+				digits[i], i+len(s)-len(digits))
+		}
+	}
+	if digits == "" {
+		return nil, fmt.Errorf("invalid BCD digits: %q", s)
+	}
+
+	sign := byte(BCD_PLUS)
+	if neg {
+		sign = BCD_MINUS
+	}
+	var b []byte
+	if c.Unpacked {
+		b = make([]byte, len(digits))
+		for i := range b {
+			b[i] = digits[i] - '0'
+		}
+		if c.Signed {
+			b[len(b)-1] |= sign << 4
+		}
+	} else {
+		nibbles := []byte(digits)
+		for i := range nibbles {
+			nibbles[i] -= '0'
+		}
+		if c.Signed {
+			nibbles = append(nibbles, sign)
+		}
+		if len(nibbles)%2 != 0 {
+			nibbles = append([]byte{0}, nibbles...)
+		}
+		b = make([]byte, len(nibbles)/2)
+		for i := range b {
+			b[i] = nibbles[2*i]<<4 | nibbles[2*i+1]
+		}
+	}
+
+	if c.Size > 0 {
+		if len(b) > c.Size {
+			return nil, fmt.Errorf("%s overflows %d bytes BCD", s, c.Size)
+		}
+		b = append(make([]byte, c.Size-len(b)), b...)
+	}
+	return b, nil
+}
+
+// DecodeDigits returns the decimal digits of b BCD including the leading
+// zeroes, prefixed by ``-'' if it is negative. The error tells the index of
+// the first invalid byte.
+func (c BCD) DecodeDigits(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", fmt.Errorf("empty BCD")
+	}
+	if i := c.IndexInvalid(b); i >= 0 {
+		return "", fmt.Errorf("invalid BCD byte %02X at index %d", b[i], i)
+	}
+
+	var sb strings.Builder
+	var sign byte
+	for i, x := range b {
+		hi, lo := x>>4, x&0x0F
+		last := c.Signed && i == len(b)-1
+		switch {
+		case c.Unpacked && last:
+			sign = hi
+			sb.WriteByte('0' + lo)
+		case c.Unpacked:
+			sb.WriteByte('0' + lo)
+		case last:
+			sign = lo
+			sb.WriteByte('0' + hi)
+		default:
+			sb.WriteByte('0' + hi)
+			sb.WriteByte('0' + lo)
+		}
+	}
+	if sign == 0xB || sign == BCD_MINUS {
+		return "-" + sb.String(), nil
+	}
+	return sb.String(), nil
+}
+
+// Decode returns the value of b BCD.
+func (c BCD) Decode(b []byte) (int64, error) {
+	s, err := c.DecodeDigits(b)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("BCD overflows int64: %s", s)
+	}
+	return n, nil
+}
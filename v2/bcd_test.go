@@ -0,0 +1,108 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BCD", func() {
+	DescribeTable("roundtrip", func(c BCD, n int64, b []byte) {
+		enc, err := c.Encode(n)
+		Expect(err).To(Succeed())
+		Expect(enc).To(EqualBytes(b))
+		Expect(c.IndexInvalid(b)).To(Equal(-1))
+		Expect(c.Decode(b)).To(Equal(n))
+	},
+		Entry("packed", BCD{}, int64(1234), []byte{0x12, 0x34}),
+		Entry("packed odd", BCD{}, int64(123), []byte{0x01, 0x23}),
+		Entry("packed zero", BCD{}, int64(0), []byte{0x00}),
+		Entry("packed size", BCD{Size: 4}, int64(1234),
+			[]byte{0x00, 0x00, 0x12, 0x34}),
+		Entry("packed max", BCD{}, int64(math.MaxInt64),
+			[]byte{0x09, 0x22, 0x33, 0x72, 0x03, 0x68, 0x54, 0x77, 0x58, 0x07}),
+		Entry("signed", BCD{Signed: true}, int64(123), []byte{0x12, 0x3C}),
+		Entry("signed negative", BCD{Signed: true}, int64(-1234),
+			[]byte{0x01, 0x23, 0x4D}),
+		Entry("signed size", BCD{Signed: true, Size: 3}, int64(-5),
+			[]byte{0x00, 0x00, 0x5D}),
+		Entry("unpacked", BCD{Unpacked: true}, int64(1234),
+			[]byte{0x01, 0x02, 0x03, 0x04}),
+		Entry("unpacked size", BCD{Unpacked: true, Size: 3}, int64(7),
+			[]byte{0x00, 0x00, 0x07}),
+		Entry("unpacked signed", BCD{Unpacked: true, Signed: true}, int64(-123),
+			[]byte{0x01, 0x02, 0xD3}),
+	)
+
+	It("should decode other sign nibbles", func() {
+		c := BCD{Signed: true}
+		Expect(c.Decode([]byte{0x12, 0x3F})).To(Equal(int64(123)))
+		Expect(c.Decode([]byte{0x12, 0x3A})).To(Equal(int64(123)))
+		Expect(c.Decode([]byte{0x12, 0x3B})).To(Equal(int64(-123)))
+	})
+
+	It("should keep leading zeroes of digits", func() {
+		c := BCD{Size: 3}
+		b, err := c.EncodeDigits("01234")
+		Expect(err).To(Succeed())
+		Expect(b).To(EqualBytes([]byte{0x00, 0x12, 0x34}))
+		Expect(c.DecodeDigits(b)).To(Equal("001234"))
+
+		c = BCD{Signed: true}
+		b, err = c.EncodeDigits("-0012")
+		Expect(err).To(Succeed())
+		Expect(b).To(EqualBytes([]byte{0x00, 0x01, 0x2D}))
+		Expect(c.DecodeDigits(b)).To(Equal("-00012"))
+		b, err = c.EncodeDigits("+7")
+		Expect(err).To(Succeed())
+		Expect(b).To(EqualBytes([]byte{0x7C}))
+	})
+
+	DescribeTable("IndexInvalid", func(c BCD, b []byte, i int) {
+		Expect(c.IndexInvalid(b)).To(Equal(i))
+	},
+		Entry("packed", BCD{}, []byte{0x12, 0x3A, 0x45}, 1),
+		Entry("packed high", BCD{}, []byte{0xA0}, 0),
+		Entry("signed sign", BCD{Signed: true}, []byte{0x12, 0x34}, 1),
+		Entry("signed digit", BCD{Signed: true}, []byte{0x1C, 0x3C}, 0),
+		Entry("unpacked", BCD{Unpacked: true}, []byte{0x01, 0x12}, 1),
+		Entry("unpacked sign", BCD{Unpacked: true, Signed: true},
+			[]byte{0x01, 0x02}, 1),
+		Entry("empty", BCD{}, []byte{}, -1),
+	)
+
+	DescribeTable("encode error", func(c BCD, n int64, msg string) {
+		_, err := c.Encode(n)
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("negative", BCD{}, int64(-1), "negative value: -1"),
+		Entry("overflow", BCD{Size: 1}, int64(123), "123 overflows 1 bytes BCD"),
+		Entry("signed overflow", BCD{Signed: true, Size: 1}, int64(12),
+			"12 overflows 1 bytes BCD"),
+	)
+
+	DescribeTable("encode digits error", func(c BCD, s, msg string) {
+		_, err := c.EncodeDigits(s)
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("invalid", BCD{}, "12a", "invalid digit 'a' at index 2"),
+		Entry("unsigned sign", BCD{}, "-1", "invalid digit '-' at index 0"),
+		Entry("signed invalid", BCD{Signed: true}, "-1 ",
+			"invalid digit ' ' at index 2"),
+		Entry("empty", BCD{}, "", `invalid BCD digits: ""`),
+		Entry("sign only", BCD{Signed: true}, "-", `invalid BCD digits: "-"`),
+	)
+
+	DescribeTable("decode error", func(c BCD, b []byte, msg string) {
+		_, err := c.Decode(b)
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("invalid", BCD{}, []byte{0x12, 0x3A}, "invalid BCD byte 3A at index 1"),
+		Entry("empty", BCD{}, []byte{}, "empty BCD"),
+		Entry("overflow", BCD{}, []byte{0x99, 0x99, 0x99, 0x99, 0x99, 0x99,
+			0x99, 0x99, 0x99, 0x99}, "BCD overflows int64: 99999999999999999999"),
+	)
+})
@@ -0,0 +1,782 @@
+package util
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BinaryError is the error of EncodeBinary and DecodeBinary, it tells the
+// field and the byte offset of the problem.
+type BinaryError struct {
+	// Field path, e.g. ``Header.Items[2].Len''
+	Field  string
+	Offset int
+	Err    error
+}
+
+// Error returns the error message, e.g.
+//     Header.Len at offset 3: value 70000 overflows 2 bytes
+// The field is omitted if it is empty.
+func (e *BinaryError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+	}
+	return fmt.Sprintf("%s at offset %d: %v", e.Field, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *BinaryError) Unwrap() error {
+	return e.Err
+}
+
+// binOpts is the parsed ``bin'' struct tag.
+type binOpts struct {
+	skip   bool
+	le     bool
+	size   int
+	bits   int
+	prefix int
+	rest   bool
+}
+
+func parseBinTag(tag string) (binOpts, error) {
+	var o binOpts
+	if tag == "-" {
+		o.skip = true
+		return o, nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		kv := strings.SplitN(opt, "=", 2)
+		var n int
+		if len(kv) == 2 {
+			var err error
+			if n, err = strconv.Atoi(kv[1]); err != nil || n <= 0 {
+				return o, fmt.Errorf("invalid bin tag option: %q", opt)
+			}
+		}
+		switch {
+		case opt == "":
+		case opt == "be":
+			o.le = false
+		case opt == "le":
+			o.le = true
+		case opt == "rest":
+			o.rest = true
+		case kv[0] == "size" && len(kv) == 2:
+			o.size = n
+		case kv[0] == "bits" && len(kv) == 2 && n <= 64:
+			o.bits = n
+		case kv[0] == "prefix" && len(kv) == 2 && n <= 8:
+			o.prefix = n
+		default:
+			return o, fmt.Errorf("invalid bin tag option: %q", opt)
+		}
+	}
+	return o, nil
+}
+
+// elem returns the options for array or slice elements.
+func (o binOpts) elem() binOpts {
+	return binOpts{le: o.le}
+}
+
+// naturalSize returns the byte size of basic kinds, or 0 for other kinds.
+func naturalSize(k reflect.Kind) int {
+	switch k {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		return 1
+	case reflect.Int16, reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 4
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64,
+		reflect.Float64:
+		return 8
+	}
+	return 0
+}
+
+func putUint(b []byte, v uint64, n int, le bool) []byte {
+	for i := 0; i < n; i++ {
+		s := 8 * (n - 1 - i)
+		if le {
+			s = 8 * i
+		}
+		b = append(b, byte(v>>s))
+	}
+	return b
+}
+
+func getUint(b []byte, le bool) uint64 {
+	var v uint64
+	for i := range b {
+		j := i
+		if le {
+			j = len(b) - 1 - i
+		}
+		v = v<<8 | uint64(b[j])
+	}
+	return v
+}
+
+var binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+
+// binaryMarshaler returns v as encoding.BinaryMarshaler, using its address
+// for pointer receiver if v is addressable, or nil if it isn't one. Pointer
+// fields are never marshalers.
+func binaryMarshaler(v reflect.Value) encoding.BinaryMarshaler {
+	if v.Kind() == reflect.Ptr {
+		return nil
+	}
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	m, _ := v.Interface().(encoding.BinaryMarshaler)
+	return m
+}
+
+// marshalerSize returns the encoded length of t zero value, or 0 if it is
+// not a BinaryMarshaler or fails to encode.
+func marshalerSize(t reflect.Type) int {
+	m, ok := reflect.New(t).Interface().(encoding.BinaryMarshaler)
+	if !ok {
+		return 0
+	}
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
+
+// binSize returns the minimum encoded size of t with o options, for checking
+// a length prefix against the remaining bytes before allocating.
+func binSize(t reflect.Type, o binOpts) int {
+	k := t.Kind()
+	if k != reflect.Ptr && reflect.PtrTo(t).Implements(binaryUnmarshalerType) {
+		switch {
+		case o.size > 0:
+			return o.size
+		case o.prefix > 0 || o.rest:
+			return o.prefix
+		}
+		return marshalerSize(t)
+	}
+	switch k {
+	case reflect.String:
+		if o.size > 0 {
+			return o.size
+		}
+		return o.prefix
+	case reflect.Slice:
+		return o.prefix + o.size*binSize(t.Elem(), o.elem())
+	case reflect.Array:
+		return t.Len() * binSize(t.Elem(), o.elem())
+	case reflect.Struct:
+		n, bits := 0, 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fo, err := parseBinTag(f.Tag.Get("bin"))
+			if f.PkgPath != "" || err != nil || fo.skip {
+				continue
+			}
+			if fo.bits > 0 {
+				bits += fo.bits
+			} else {
+				n += binSize(f.Type, fo)
+			}
+		}
+		return n + bits/8
+	}
+	if o.size > 0 && naturalSize(k) > 0 {
+		return o.size
+	}
+	return naturalSize(k)
+}
+
+// binSpan is the encoded bytes of a field, for DumpBinary.
+type binSpan struct {
+	name     string
+	off, end int
+}
+
+//============================================================================
+
+// EncodeBinary encodes v struct into fixed layout binary frame, driven by
+// the ``bin'' struct tag of its exported fields:
+//     `bin:"-"`          skip the field
+//     `bin:"le"`         little endian, the default is big endian ``be''
+//     `bin:"size=3"`     integer size in bytes, fixed string length padded
+//                        with zeroes, fixed slice length, or exact length of
+//                        BinaryMarshaler output
+//     `bin:"bits=4"`     bit-packed unsigned integer or bool, consecutive bit
+//                        fields are packed from the most significant bit and
+//                        must end at a byte boundary
+//     `bin:"prefix=1"`   length prefixed string, slice or BinaryMarshaler,
+//                        the prefix size is in bytes and counts elements
+//     `bin:"rest"`       string, slice or BinaryMarshaler that takes the rest
+//                        of the frame
+// Integers, floats and bools have their Go type size unless the size option
+// is given, int and uint are 8 bytes. Arrays and nested structs are encoded
+// in place, and fields implementing encoding.BinaryMarshaler, such as Date
+// and Version, are encoded using it, even with pointer receiver. Without
+// size, prefix or rest option, their length must be the same as of their
+// zero value. The error is *BinaryError with the field path relative to v.
+func EncodeBinary(v interface{}) ([]byte, error) {
+	e := &binEncoder{}
+	if err := e.top(v); err != nil {
+		return nil, err
+	}
+	return e.b, nil
+}
+
+// DumpBinary returns the encoded fields of v, one field per line with its
+// offset and HexWrap formatted bytes, e.g.
+//     0000 Version  [.2]
+//     0001 Flags    [a.]
+//     0002 Name     [48 65 6c 6c 6f .. .. ..]
+// Consecutive bit fields are shown together like ``Ver|Type''.
+func DumpBinary(v interface{}) (string, error) {
+	e := &binEncoder{dump: true}
+	if err := e.top(v); err != nil {
+		return "", err
+	}
+
+	w := 0
+	for _, s := range e.spans {
+		if len(s.name) > w {
+			w = len(s.name)
+		}
+	}
+	lines := make([]string, len(e.spans))
+	for i, s := range e.spans {
+		pre := fmt.Sprintf("%04x %-*s [", s.off, w, s.name)
+		lines[i] = strings.TrimSuffix(
+			HexWrap(e.b[s.off:s.end], pre, "]", "  ", 80, 80), "\n")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+type binEncoder struct {
+	b     []byte
+	bits  uint64
+	nbits int
+	dump  bool
+	spans []binSpan
+	// pending bit fields span
+	bitSpan binSpan
+}
+
+func (e *binEncoder) errorf(name, format string, a ...interface{}) error {
+	return &BinaryError{
+		Field:  name,
+		Offset: len(e.b),
+		Err:    fmt.Errorf(format, a...),
+	}
+}
+
+func (e *binEncoder) top(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return &BinaryError{Err: fmt.Errorf("expecting struct, got %T", v)}
+	}
+	if !rv.CanAddr() {
+		// copy to reach pointer receiver BinaryMarshaler
+		pv := reflect.New(rv.Type())
+		pv.Elem().Set(rv)
+		rv = pv.Elem()
+	}
+	if err := e.fields(rv, ""); err != nil {
+		return err
+	}
+	if e.nbits != 0 {
+		return e.errorf(e.bitSpan.name, "bit fields not byte aligned")
+	}
+	return nil
+}
+
+func (e *binEncoder) fields(v reflect.Value, name string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fname := f.Name
+		if name != "" {
+			fname = name + "." + f.Name
+		}
+		o, err := parseBinTag(f.Tag.Get("bin"))
+		if err != nil {
+			return e.errorf(fname, "%v", err)
+		}
+		if o.skip {
+			continue
+		}
+		if err := e.field(v.Field(i), o, fname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field encodes v, recording its span unless it is a nested struct.
+func (e *binEncoder) field(v reflect.Value, o binOpts, name string) error {
+	if o.bits > 0 {
+		return e.encodeBits(v, o, name)
+	}
+	if e.nbits != 0 {
+		return e.errorf(e.bitSpan.name, "bit fields not byte aligned")
+	}
+	off := len(e.b)
+	nested := v.Kind() == reflect.Struct && binaryMarshaler(v) == nil
+	if err := e.encode(v, o, name); err != nil {
+		return err
+	}
+	if e.dump && !nested {
+		e.spans = append(e.spans, binSpan{name, off, len(e.b)})
+	}
+	return nil
+}
+
+func (e *binEncoder) encodeBits(v reflect.Value, o binOpts, name string) error {
+	var u uint64
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			u = 1
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u = v.Uint()
+	default:
+		return e.errorf(name, "bits option on %s", v.Type())
+	}
+	if o.bits < 64 && u>>o.bits != 0 {
+		return e.errorf(name, "value %d overflows %d bits", u, o.bits)
+	}
+
+	if e.nbits == 0 {
+		e.bitSpan = binSpan{name: name, off: len(e.b)}
+	} else {
+		e.bitSpan.name += "|" + name[strings.LastIndexByte(name, '.')+1:]
+	}
+	for i := o.bits - 1; i >= 0; i-- {
+		e.bits = e.bits<<1 | (u >> i & 1)
+		e.nbits++
+		if e.nbits == 8 {
+			e.b = append(e.b, byte(e.bits))
+			e.bits, e.nbits = 0, 0
+		}
+	}
+	if e.nbits == 0 && e.dump {
+		e.bitSpan.end = len(e.b)
+		e.spans = append(e.spans, e.bitSpan)
+	}
+	return nil
+}
+
+// blob writes data with the size, prefix or rest option.
+func (e *binEncoder) blob(data []byte, o binOpts, name string) error {
+	switch {
+	case o.size > 0:
+		if len(data) != o.size {
+			return e.errorf(name, "length %d, expecting %d", len(data), o.size)
+		}
+	case o.prefix > 0:
+		if err := e.prefix(len(data), o, name); err != nil {
+			return err
+		}
+	case !o.rest:
+		return e.errorf(name, "needs size, prefix or rest option")
+	}
+	e.b = append(e.b, data...)
+	return nil
+}
+
+func (e *binEncoder) prefix(n int, o binOpts, name string) error {
+	if o.prefix < 8 && uint64(n)>>(8*o.prefix) != 0 {
+		return e.errorf(name, "length %d overflows %d bytes prefix",
+			n, o.prefix)
+	}
+	e.b = putUint(e.b, uint64(n), o.prefix, o.le)
+	return nil
+}
+
+func (e *binEncoder) encode(v reflect.Value, o binOpts, name string) error {
+	if m := binaryMarshaler(v); m != nil {
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return e.errorf(name, "%v", err)
+		}
+		if o.size == 0 && o.prefix == 0 && !o.rest {
+			o.size = marshalerSize(v.Type())
+		}
+		return e.blob(data, o, name)
+	}
+
+	k := v.Kind()
+	n := naturalSize(k)
+	if o.size > 0 && n > 0 {
+		if k == reflect.Float32 || k == reflect.Float64 || k == reflect.Bool {
+			return e.errorf(name, "size option on %s", v.Type())
+		} else if o.size > 8 {
+			return e.errorf(name, "invalid size: %d", o.size)
+		}
+		n = o.size
+	}
+
+	switch k {
+	case reflect.Bool:
+		var u uint64
+		if v.Bool() {
+			u = 1
+		}
+		e.b = append(e.b, byte(u))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		i := v.Int()
+		if n < 8 && (i < -1<<(8*n-1) || i >= 1<<(8*n-1)) {
+			return e.errorf(name, "value %d overflows %d bytes", i, n)
+		}
+		e.b = putUint(e.b, uint64(i), n, o.le)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u := v.Uint()
+		if n < 8 && u>>(8*n) != 0 {
+			return e.errorf(name, "value %d overflows %d bytes", u, n)
+		}
+		e.b = putUint(e.b, u, n, o.le)
+	case reflect.Float32:
+		e.b = putUint(e.b, uint64(math.Float32bits(float32(v.Float()))), 4,
+			o.le)
+	case reflect.Float64:
+		e.b = putUint(e.b, math.Float64bits(v.Float()), 8, o.le)
+	case reflect.String:
+		s := v.String()
+		if o.size > 0 {
+			if len(s) > o.size {
+				return e.errorf(name, "string length %d exceeds %d",
+					len(s), o.size)
+			}
+			e.b = append(e.b, s...)
+			e.b = append(e.b, make([]byte, o.size-len(s))...)
+			return nil
+		}
+		return e.blob([]byte(s), o, name)
+	case reflect.Array:
+		return e.elems(v, o, name)
+	case reflect.Slice:
+		switch {
+		case o.size > 0:
+			if v.Len() != o.size {
+				return e.errorf(name, "length %d, expecting %d",
+					v.Len(), o.size)
+			}
+		case o.prefix > 0:
+			if err := e.prefix(v.Len(), o, name); err != nil {
+				return err
+			}
+		case !o.rest:
+			return e.errorf(name, "needs size, prefix or rest option")
+		}
+		return e.elems(v, o, name)
+	case reflect.Struct:
+		return e.fields(v, name)
+	default:
+		return e.errorf(name, "unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *binEncoder) elems(v reflect.Value, o binOpts, name string) error {
+	if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+		e.b = append(e.b, v.Bytes()...)
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		err := e.encode(v.Index(i), o.elem(), fmt.Sprintf("%s[%d]", name, i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//============================================================================
+
+// DecodeBinary decodes b binary frame into v pointer to struct, see
+// EncodeBinary for the struct tags. The whole frame must be consumed.
+// The error is *BinaryError.
+func DecodeBinary(b []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+		return &BinaryError{Err: fmt.Errorf("expecting pointer to struct, "+
+			"got %T", v)}
+	}
+	rv = rv.Elem()
+	d := &binDecoder{b: b}
+	if err := d.fields(rv, ""); err != nil {
+		return err
+	}
+	if d.off != len(b) {
+		return d.errorf("", "%d trailing bytes", len(b)-d.off)
+	}
+	return nil
+}
+
+type binDecoder struct {
+	b   []byte
+	off int
+	bit int
+}
+
+func (d *binDecoder) errorf(name, format string, a ...interface{}) error {
+	return &BinaryError{
+		Field:  name,
+		Offset: d.off,
+		Err:    fmt.Errorf(format, a...),
+	}
+}
+
+// take returns the next n bytes.
+func (d *binDecoder) take(n int, name string) ([]byte, error) {
+	if n > len(d.b)-d.off {
+		return nil, d.errorf(name, "short buffer: need %d bytes, have %d",
+			n, len(d.b)-d.off)
+	}
+	b := d.b[d.off : d.off+n]
+	d.off += n
+	return b, nil
+}
+
+func (d *binDecoder) fields(v reflect.Value, name string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		fname := f.Name
+		if name != "" {
+			fname = name + "." + f.Name
+		}
+		o, err := parseBinTag(f.Tag.Get("bin"))
+		if err != nil {
+			return d.errorf(fname, "%v", err)
+		}
+		if o.skip {
+			continue
+		}
+		if o.bits > 0 {
+			err = d.decodeBits(v.Field(i), o, fname)
+		} else if d.bit != 0 {
+			err = d.errorf(fname, "bit fields not byte aligned")
+		} else {
+			err = d.decode(v.Field(i), o, fname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *binDecoder) decodeBits(v reflect.Value, o binOpts, name string) error {
+	var u uint64
+	for i := 0; i < o.bits; i++ {
+		if d.off >= len(d.b) {
+			return d.errorf(name, "short buffer: need %d bits", o.bits-i)
+		}
+		u = u<<1 | uint64(d.b[d.off]>>(7-d.bit)&1)
+		if d.bit++; d.bit == 8 {
+			d.bit = 0
+			d.off++
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(u != 0)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		if v.OverflowUint(u) {
+			return d.errorf(name, "value %d overflows %s", u, v.Type())
+		}
+		v.SetUint(u)
+	default:
+		return d.errorf(name, "bits option on %s", v.Type())
+	}
+	return nil
+}
+
+// length returns the length from size, prefix or rest option. The prefix
+// counts elements of at least unit bytes each.
+func (d *binDecoder) length(o binOpts, unit int, name string) (int, error) {
+	switch {
+	case o.size > 0:
+		return o.size, nil
+	case o.prefix > 0:
+		b, err := d.take(o.prefix, name)
+		if err != nil {
+			return 0, err
+		}
+		n := getUint(b, o.le)
+		if unit < 1 {
+			unit = 1
+		}
+		if rem := len(d.b) - d.off; n > uint64(rem/unit) {
+			d.off -= o.prefix
+			if unit == 1 {
+				return 0, d.errorf(name,
+					"length %d exceeds remaining %d bytes", n, rem)
+			}
+			return 0, d.errorf(name, "length %d of %d-byte elements "+
+				"exceeds remaining %d bytes", n, unit, rem)
+		}
+		return int(n), nil
+	case o.rest:
+		return len(d.b) - d.off, nil
+	}
+	return 0, d.errorf(name, "needs size, prefix or rest option")
+}
+
+func (d *binDecoder) decode(v reflect.Value, o binOpts, name string) error {
+	if pv := v.Addr(); pv.Type().Implements(binaryUnmarshalerType) &&
+		v.Kind() != reflect.Ptr {
+		if o.size == 0 && o.prefix == 0 && !o.rest {
+			o.size = marshalerSize(v.Type())
+		}
+		n, err := d.length(o, 1, name)
+		if err != nil {
+			return err
+		}
+		off := d.off
+		b, err := d.take(n, name)
+		if err != nil {
+			return err
+		}
+		err = pv.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b)
+		if err != nil {
+			d.off = off
+			return d.errorf(name, "%v", err)
+		}
+		return nil
+	}
+
+	k := v.Kind()
+	n := naturalSize(k)
+	if o.size > 0 && n > 0 {
+		if k == reflect.Float32 || k == reflect.Float64 || k == reflect.Bool {
+			return d.errorf(name, "size option on %s", v.Type())
+		} else if o.size > 8 {
+			return d.errorf(name, "invalid size: %d", o.size)
+		}
+		n = o.size
+	}
+
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32,
+		reflect.Float64:
+		off := d.off
+		b, err := d.take(n, name)
+		if err != nil {
+			return err
+		}
+		u := getUint(b, o.le)
+		switch k {
+		case reflect.Bool:
+			v.SetBool(u != 0)
+		case reflect.Float32:
+			v.SetFloat(float64(math.Float32frombits(uint32(u))))
+		case reflect.Float64:
+			v.SetFloat(math.Float64frombits(u))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			i := int64(u<<(64-8*n)) >> (64 - 8*n)
+			if v.OverflowInt(i) {
+				d.off = off
+				return d.errorf(name, "value %d overflows %s", i, v.Type())
+			}
+			v.SetInt(i)
+		default:
+			if v.OverflowUint(u) {
+				d.off = off
+				return d.errorf(name, "value %d overflows %s", u, v.Type())
+			}
+			v.SetUint(u)
+		}
+	case reflect.String:
+		n, err := d.length(o, 1, name)
+		if err != nil {
+			return err
+		}
+		b, err := d.take(n, name)
+		if err != nil {
+			return err
+		}
+		if o.size > 0 {
+			b = []byte(strings.TrimRight(string(b), "\x00"))
+		}
+		v.SetString(string(b))
+	case reflect.Array:
+		return d.elems(v, o, name)
+	case reflect.Slice:
+		if o.rest && v.Type().Elem().Kind() != reflect.Uint8 {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			for i := 0; d.off < len(d.b); i++ {
+				e := reflect.New(v.Type().Elem()).Elem()
+				ename := fmt.Sprintf("%s[%d]", name, i)
+				off := d.off
+				if err := d.decode(e, o.elem(), ename); err != nil {
+					return err
+				}
+				if d.off == off {
+					return d.errorf(ename, "zero size element of %s",
+						v.Type().Elem())
+				}
+				v.Set(reflect.Append(v, e))
+			}
+			return nil
+		}
+		n, err := d.length(o, binSize(v.Type().Elem(), o.elem()), name)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		return d.elems(v, o, name)
+	case reflect.Struct:
+		return d.fields(v, name)
+	default:
+		return d.errorf(name, "unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (d *binDecoder) elems(v reflect.Value, o binOpts, name string) error {
+	if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+		b, err := d.take(v.Len(), name)
+		if err != nil {
+			return err
+		}
+		copy(v.Bytes(), b)
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		err := d.decode(v.Index(i), o.elem(), fmt.Sprintf("%s[%d]", name, i))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package util_test
+
+import (
+	"errors"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type binHeader struct {
+	Ver  uint8 `bin:"bits=2"`
+	Ack  bool  `bin:"bits=1"`
+	Type uint8 `bin:"bits=5"`
+	Len  uint16
+}
+
+type binFrame struct {
+	Header  binHeader
+	Seq     uint32 `bin:"size=3"`
+	Temp    int16  `bin:"le"`
+	Name    string `bin:"size=6"`
+	Version Version
+	Date    Date     `bin:"size=4"`
+	Values  []uint16 `bin:"prefix=1,le"`
+	Skip    int      `bin:"-"`
+	secret  int
+	Tail    []byte `bin:"rest"`
+}
+
+// binPoint has pointer receiver BinaryMarshaler, encoded as Y then X.
+type binPoint struct {
+	X, Y uint8
+}
+
+func (p *binPoint) MarshalBinary() ([]byte, error) {
+	return []byte{p.Y, p.X}, nil
+}
+
+func (p *binPoint) UnmarshalBinary(b []byte) error {
+	if len(b) != 2 {
+		return errors.New("invalid point")
+	}
+	p.X, p.Y = b[1], b[0]
+	return nil
+}
+
+// binSkipped has no encoded field.
+type binSkipped struct {
+	A uint8 `bin:"-"`
+}
+
+var _ = Describe("Binary codec", func() {
+	date := MakeDate(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC))
+	dateBytes, _ := date.MarshalBinary()
+	frame := binFrame{
+		Header:  binHeader{Ver: 2, Ack: true, Type: 3, Len: 0x0102},
+		Seq:     0x0A0B0C,
+		Temp:    -2,
+		Name:    "abc",
+		Version: MakeVersion(1, 2, 3),
+		Date:    date,
+		Values:  []uint16{1, 0x0203},
+		Tail:    []byte{0xFF},
+	}
+	data := append([]byte{
+		0xA3, 0x01, 0x02,
+		0x0A, 0x0B, 0x0C,
+		0xFE, 0xFF,
+		'a', 'b', 'c', 0, 0, 0,
+		1, 2, 3,
+	}, dateBytes...)
+	data = append(data, 2, 0x01, 0x00, 0x03, 0x02, 0xFF)
+
+	It("should encode", func() {
+		b, err := EncodeBinary(frame)
+		Expect(err).To(Succeed())
+		Expect(b).To(EqualBytes(data))
+
+		b, err = EncodeBinary(&frame)
+		Expect(err).To(Succeed())
+		Expect(b).To(EqualBytes(data))
+	})
+
+	It("should decode", func() {
+		var f binFrame
+		Expect(DecodeBinary(data, &f)).To(Succeed())
+		Expect(f.Date.Equal(date)).To(BeTrue())
+		f.Date = frame.Date
+		Expect(f).To(Equal(frame))
+	})
+
+	It("should dump", func() {
+		s, err := DumpBinary(frame)
+		Expect(err).To(Succeed())
+		Expect(s).To(Equal(`0000 Header.Ver|Ack|Type [a3]
+0001 Header.Len          [.1 .2]
+0003 Seq                 [.a .b .c]
+0006 Temp                [fe ff]
+0008 Name                [61 62 63 .. .. ..]
+000e Version             [.1 .2 .3]
+0011 Date                [` + FancyHex(dateBytes) + `]
+0015 Values              [.2 .1 .. .3 .2]
+001a Tail                [ff]`))
+	})
+
+	It("should wrap long dump", func() {
+		s, err := DumpBinary(struct {
+			Data []byte `bin:"size=30"`
+		}{make([]byte, 30)})
+		Expect(err).To(Succeed())
+		Expect(s).To(Equal("0000 Data [\n" +
+			"  .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. .. " +
+			".. .. .. .. ..\n  .. .. .. ..\n]"))
+	})
+
+	It("should roundtrip signed and arrays", func() {
+		type s struct {
+			A int32 `bin:"size=3"`
+			B [2]int8
+			C float32
+			D float64  `bin:"le"`
+			E []string `bin:"size=2"`
+		}
+		type wrap struct {
+			S    s
+			Strs []string `bin:"rest"`
+		}
+		in := wrap{}
+		in.S = s{A: -70000, B: [2]int8{-1, 2}, C: 1.5, D: -0.25,
+			E: []string{}}
+		b, err := EncodeBinary(in)
+		Expect(b).To(BeNil())
+		Expect(err).To(MatchError("S.E at offset 17: length 0, expecting 2"))
+
+		type elem struct {
+			Str string `bin:"prefix=1"`
+		}
+		type ok struct {
+			A int32 `bin:"size=3"`
+			B [2]int8
+			C float32
+			D float64 `bin:"le"`
+			E []elem  `bin:"rest"`
+		}
+		o := ok{A: -70000, B: [2]int8{-1, 2}, C: 1.5, D: -0.25,
+			E: []elem{{"x"}, {""}}}
+		b, err = EncodeBinary(o)
+		Expect(err).To(Succeed())
+		Expect(b).To(HaveLen(3 + 2 + 4 + 8 + 3))
+		var r ok
+		Expect(DecodeBinary(b, &r)).To(Succeed())
+		Expect(r).To(Equal(o))
+	})
+
+	It("should roundtrip pointer receiver marshaler", func() {
+		type points struct {
+			P  binPoint
+			Ps []binPoint `bin:"prefix=1"`
+		}
+		in := points{binPoint{1, 2}, []binPoint{{3, 4}}}
+		x := []byte{2, 1, 1, 4, 3}
+		Expect(EncodeBinary(in)).To(EqualBytes(x))
+		Expect(EncodeBinary(&in)).To(EqualBytes(x))
+
+		var r points
+		Expect(DecodeBinary(x, &r)).To(Succeed())
+		Expect(r).To(Equal(in))
+	})
+
+	DescribeTable("encode error", func(v interface{}, msg string) {
+		_, err := EncodeBinary(v)
+		Expect(err).To(MatchError(msg))
+		var be *BinaryError
+		Expect(errors.As(err, &be)).To(BeTrue())
+	},
+		Entry("not struct", 1, "offset 0: expecting struct, got int"),
+		Entry("overflow", struct {
+			A uint8
+			B uint16 `bin:"size=1"`
+		}{1, 300}, "B at offset 1: value 300 overflows 1 bytes"),
+		Entry("signed overflow", struct {
+			A int32 `bin:"size=1"`
+		}{-129}, "A at offset 0: value -129 overflows 1 bytes"),
+		Entry("bits overflow", struct {
+			A uint8 `bin:"bits=3"`
+		}{8}, "A at offset 0: value 8 overflows 3 bits"),
+		Entry("unaligned bits", struct {
+			A uint8 `bin:"bits=3"`
+			B uint8
+		}{}, "A at offset 0: bit fields not byte aligned"),
+		Entry("unaligned bits end", struct {
+			A uint8 `bin:"bits=4"`
+			B uint8 `bin:"bits=3"`
+		}{}, "A|B at offset 0: bit fields not byte aligned"),
+		Entry("bits on signed", struct {
+			A int8 `bin:"bits=8"`
+		}{}, "A at offset 0: bits option on int8"),
+		Entry("long string", struct {
+			A string `bin:"size=2"`
+		}{"abc"}, "A at offset 0: string length 3 exceeds 2"),
+		Entry("string size", struct {
+			A string
+		}{"abc"}, "A at offset 0: needs size, prefix or rest option"),
+		Entry("prefix overflow", struct {
+			A []byte `bin:"prefix=1"`
+		}{make([]byte, 256)}, "A at offset 0: length 256 overflows 1 bytes prefix"),
+		Entry("marshaler size", struct {
+			V Version `bin:"size=4"`
+		}{}, "V at offset 0: length 3, expecting 4"),
+		Entry("marshaler error", struct {
+			D Date `bin:"size=4"`
+		}{MakeDate(time.Date(5000, 1, 1, 0, 0, 0, 0, time.UTC))},
+			"D at offset 0: year outside range 0-4094: 5000"),
+		Entry("nested", struct {
+			A uint8
+			B struct {
+				C []int8 `bin:"size=2"`
+			}
+		}{}, "B.C at offset 1: length 0, expecting 2"),
+		Entry("unsupported", struct {
+			M map[string]int
+		}{}, "M at offset 0: unsupported type map[string]int"),
+		Entry("float size", struct {
+			F float64 `bin:"size=4"`
+		}{}, "F at offset 0: size option on float64"),
+		Entry("invalid tag", struct {
+			A uint8 `bin:"size=x"`
+		}{}, `A at offset 0: invalid bin tag option: "size=x"`),
+	)
+
+	DescribeTable("decode error", func(b []byte, v interface{}, msg string) {
+		err := DecodeBinary(b, v)
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("not pointer", []byte{}, binHeader{},
+			"offset 0: expecting pointer to struct, got util_test.binHeader"),
+		Entry("short", []byte{0xA3, 0x01}, &binHeader{},
+			"Len at offset 1: short buffer: need 2 bytes, have 1"),
+		Entry("short bits", []byte{}, &binHeader{},
+			"Ver at offset 0: short buffer: need 2 bits"),
+		Entry("trailing", []byte{0xA3, 0x01, 0x02, 0x03}, &binHeader{},
+			"offset 3: 1 trailing bytes"),
+		Entry("prefix", []byte{0x01, 0x03, 0x61}, &struct {
+			A uint8
+			S string `bin:"prefix=1"`
+		}{}, "S at offset 1: length 3 exceeds remaining 1 bytes"),
+		Entry("zero size rest", []byte{0x01, 0x02}, &struct {
+			A  uint8
+			Xs []struct{} `bin:"rest"`
+		}{}, "Xs[0] at offset 1: zero size element of struct {}"),
+		Entry("skipped rest", []byte{0x01}, &struct {
+			Xs []binSkipped `bin:"rest"`
+		}{}, "Xs[0] at offset 0: zero size element of util_test.binSkipped"),
+		Entry("prefix elements", []byte{0x02, 0x00, 0x01}, &struct {
+			V []uint16 `bin:"prefix=1"`
+		}{}, "V at offset 0: length 2 of 2-byte elements exceeds "+
+			"remaining 2 bytes"),
+		Entry("prefix structs", []byte{0x01, 0xA3, 0x01}, &struct {
+			H []binHeader `bin:"prefix=1"`
+		}{}, "H at offset 0: length 1 of 3-byte elements exceeds "+
+			"remaining 2 bytes"),
+		Entry("overflow", []byte{0x01, 0x00, 0x00}, &struct {
+			A uint16 `bin:"size=3"`
+		}{}, "A at offset 0: value 65536 overflows uint16"),
+		Entry("marshaler", []byte{0x00, 0x00, 0x00, 0x00}, &struct {
+			D Date `bin:"size=4"`
+		}{}, "D at offset 0: invalid type bits: 0000"),
+		Entry("marshaler size", []byte{0x00}, &struct {
+			D Date
+		}{}, "D at offset 0: short buffer: need 4 bytes, have 1"),
+	)
+})
@@ -0,0 +1,291 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BitField describes a field of BitLayout.
+type BitField struct {
+	Name string
+	// Bit offset of the least significant bit, bit 0 is the least
+	// significant bit of the whole register.
+	Offset int
+	// Number of bits, from 1 to 64
+	Width int
+	// Optional labels of enumerated values
+	Labels map[uint64]string
+}
+
+// Label returns the label of v value, or empty string if there is none.
+func (f BitField) Label(v uint64) string {
+	return f.Labels[v]
+}
+
+// LabelValue returns the value of label, it returns false if the label is
+// not found.
+func (f BitField) LabelValue(label string) (uint64, bool) {
+	for v, l := range f.Labels {
+		if l == label {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// BitLayout describes bit fields of a register or packed header, e.g.
+//     l := BitLayout{Size: 1, Fields: []BitField{
+//         {Name: "en", Offset: 7, Width: 1},
+//         {Name: "mode", Offset: 4, Width: 3,
+//             Labels: map[uint64]string{0: "OFF", 2: "AUTO"}},
+//         {Name: "cnt", Offset: 0, Width: 4},
+//     }}
+type BitLayout struct {
+	// Size in bytes, zero means the size of decoded bytes
+	Size int
+	// Byte order of the register, nil is binary.LittleEndian like BitString
+	// where the first byte is the least significant.
+	ByteOrder binary.ByteOrder
+	Fields    []BitField
+}
+
+// BitValue is a decoded field value.
+type BitValue struct {
+	Name  string
+	Value uint64
+	// Label of the value, empty if there is none
+	Label string
+}
+
+// BitValues is the decoded fields, in the layout fields order.
+type BitValues []BitValue
+
+// Get returns the value of name field, it returns false if there is no such
+// field.
+func (vs BitValues) Get(name string) (uint64, bool) {
+	for _, v := range vs {
+		if v.Name == name {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Map returns the values keyed by field name, suitable for Encode.
+func (vs BitValues) Map() map[string]uint64 {
+	m := make(map[string]uint64, len(vs))
+	for _, v := range vs {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+// String returns the values formatted like ``en=1 mode=2(AUTO) cnt=5''.
+func (vs BitValues) String() string {
+	ss := make([]string, len(vs))
+	for i, v := range vs {
+		ss[i] = v.Name + "=" + strconv.FormatUint(v.Value, 10)
+		if v.Label != "" {
+			ss[i] += "(" + v.Label + ")"
+		}
+	}
+	return strings.Join(ss, " ")
+}
+
+// Validate checks that all fields have valid width, fit in the layout size
+// and don't overlap each other.
+func (l BitLayout) Validate() error {
+	return l.validate(l.Size)
+}
+
+func (l BitLayout) validate(size int) error {
+	fs := l.sorted()
+	for i, f := range fs {
+		if f.Width < 1 || f.Width > 64 {
+			return fmt.Errorf("invalid bit field %q width: %d",
+				f.Name, f.Width)
+		}
+		if f.Offset < 0 || (size > 0 && f.Offset+f.Width > size*8) {
+			return fmt.Errorf("bit field %q outside %d bits", f.Name, size*8)
+		}
+		if i > 0 && fs[i-1].Offset < f.Offset+f.Width {
+			return fmt.Errorf("bit field %q overlaps %q",
+				f.Name, fs[i-1].Name)
+		}
+	}
+	return nil
+}
+
+// sorted returns the fields sorted from the most significant.
+func (l BitLayout) sorted() []BitField {
+	fs := append([]BitField(nil), l.Fields...)
+	sort.SliceStable(fs, func(i, j int) bool {
+		return fs[i].Offset > fs[j].Offset
+	})
+	return fs
+}
+
+// byteIndex returns the index of byte containing bit i of n bytes register.
+func (l BitLayout) byteIndex(i, n int) int {
+	if l.ByteOrder == binary.BigEndian {
+		return n - 1 - i/8
+	}
+	return i / 8
+}
+
+func (l BitLayout) bit(b []byte, i int) uint64 {
+	return uint64(b[l.byteIndex(i, len(b))]>>(i%8)) & 1
+}
+
+func (l BitLayout) get(b []byte, f BitField) uint64 {
+	var v uint64
+	for i := f.Width - 1; i >= 0; i-- {
+		v = v<<1 | l.bit(b, f.Offset+i)
+	}
+	return v
+}
+
+func (l BitLayout) set(b []byte, f BitField, v uint64) {
+	for i := 0; i < f.Width; i++ {
+		j := l.byteIndex(f.Offset+i, len(b))
+		m := byte(1) << ((f.Offset + i) % 8)
+		if v>>i&1 != 0 {
+			b[j] |= m
+		} else {
+			b[j] &^= m
+		}
+	}
+}
+
+func (l BitLayout) check(b []byte) error {
+	if l.Size > 0 && len(b) != l.Size {
+		return fmt.Errorf("invalid length: %d, expecting %d", len(b), l.Size)
+	}
+	return l.validate(len(b))
+}
+
+// Decode returns the field values of b bytes.
+func (l BitLayout) Decode(b []byte) (BitValues, error) {
+	if err := l.check(b); err != nil {
+		return nil, err
+	}
+	vs := make(BitValues, len(l.Fields))
+	for i, f := range l.Fields {
+		v := l.get(b, f)
+		vs[i] = BitValue{Name: f.Name, Value: v, Label: f.Label(v)}
+	}
+	return vs, nil
+}
+
+// Encode returns Size bytes with the field values set, missing fields and
+// the bits outside any field are zero.
+func (l BitLayout) Encode(vals map[string]uint64) ([]byte, error) {
+	if l.Size <= 0 {
+		return nil, fmt.Errorf("invalid layout size: %d", l.Size)
+	}
+	b := make([]byte, l.Size)
+	if err := l.EncodeInto(b, vals); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// EncodeInto sets the field values in b, keeping the other bits. This is
+// handy for read-modify-write of a register.
+func (l BitLayout) EncodeInto(b []byte, vals map[string]uint64) error {
+	if err := l.check(b); err != nil {
+		return err
+	}
+	for name := range vals {
+		if _, ok := l.field(name); !ok {
+			return fmt.Errorf("unknown bit field: %q", name)
+		}
+	}
+	for _, f := range l.Fields {
+		v, ok := vals[f.Name]
+		if !ok {
+			continue
+		}
+		if f.Width < 64 && v>>f.Width != 0 {
+			return fmt.Errorf("value %d overflows %d bits field %q",
+				v, f.Width, f.Name)
+		}
+		l.set(b, f, v)
+	}
+	return nil
+}
+
+func (l BitLayout) field(name string) (BitField, bool) {
+	for _, f := range l.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return BitField{}, false
+}
+
+// Diagram returns BitString like bits of b annotated with the fields. The
+// bits are printed from the most significant, separated at the field
+// boundaries, e.g.
+//     1 010 0101
+//     | |   `----- cnt  = 5
+//     | `--------- mode = 2 (AUTO)
+//     `----------- en   = 1
+// Bits outside any field are printed but not annotated.
+func (l BitLayout) Diagram(b []byte) (string, error) {
+	if err := l.check(b); err != nil {
+		return "", err
+	}
+
+	var bits strings.Builder
+	var cols []int
+	var fields []BitField
+	fs := l.sorted()
+	nameLen := 0
+	for i, k := len(b)*8-1, 0; i >= 0; {
+		if bits.Len() > 0 {
+			bits.WriteByte(' ')
+		}
+		// Bits down to the next field, or the whole field
+		end := -1
+		if k < len(fs) && i == fs[k].Offset+fs[k].Width-1 {
+			f := fs[k]
+			end = f.Offset - 1
+			cols = append(cols, bits.Len())
+			fields = append(fields, f)
+			if len(f.Name) > nameLen {
+				nameLen = len(f.Name)
+			}
+			k++
+		} else if k < len(fs) {
+			end = fs[k].Offset + fs[k].Width - 1
+		}
+		for ; i > end; i-- {
+			bits.WriteByte(byte('0' + l.bit(b, i)))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(bits.String())
+	width := bits.Len()
+	for k := len(fields) - 1; k >= 0; k-- {
+		line := []byte(strings.Repeat(" ", width+2))
+		for j := 0; j < k; j++ {
+			line[cols[j]] = '|'
+		}
+		line[cols[k]] = '`'
+		for j := cols[k] + 1; j < len(line); j++ {
+			line[j] = '-'
+		}
+		f := fields[k]
+		v := l.get(b, f)
+		fmt.Fprintf(&sb, "\n%s %-*s = %d", line, nameLen, f.Name, v)
+		if label := f.Label(v); label != "" {
+			fmt.Fprintf(&sb, " (%s)", label)
+		}
+	}
+	return sb.String(), nil
+}
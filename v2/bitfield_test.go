@@ -0,0 +1,119 @@
+package util_test
+
+import (
+	"encoding/binary"
+	. "github.com/hanindo/util/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BitLayout", func() {
+	status := BitLayout{Size: 1, Fields: []BitField{
+		{Name: "en", Offset: 7, Width: 1},
+		{Name: "mode", Offset: 4, Width: 3,
+			Labels: map[uint64]string{0: "OFF", 2: "AUTO"}},
+		{Name: "cnt", Offset: 0, Width: 4},
+	}}
+
+	header := BitLayout{Size: 4, ByteOrder: binary.BigEndian, Fields: []BitField{
+		{Name: "ver", Offset: 30, Width: 2},
+		{Name: "type", Offset: 24, Width: 4},
+		{Name: "len", Offset: 8, Width: 12},
+		{Name: "seq", Offset: 0, Width: 8},
+	}}
+
+	It("should decode", func() {
+		vs, err := status.Decode([]byte{0xA5})
+		Expect(err).To(Succeed())
+		Expect(vs).To(Equal(BitValues{
+			{Name: "en", Value: 1},
+			{Name: "mode", Value: 2, Label: "AUTO"},
+			{Name: "cnt", Value: 5},
+		}))
+		Expect(vs.String()).To(Equal("en=1 mode=2(AUTO) cnt=5"))
+		v, ok := vs.Get("mode")
+		Expect(v).To(Equal(uint64(2)))
+		Expect(ok).To(BeTrue())
+		_, ok = vs.Get("none")
+		Expect(ok).To(BeFalse())
+
+		vs, err = header.Decode([]byte{0x83, 0x01, 0x23, 0x7F})
+		Expect(err).To(Succeed())
+		Expect(vs.String()).To(Equal("ver=2 type=3 len=291 seq=127"))
+
+		vs, err = BitLayout{Fields: []BitField{
+			{Name: "lo", Offset: 4, Width: 8},
+		}}.Decode([]byte{0x12, 0x34})
+		Expect(err).To(Succeed())
+		Expect(vs.String()).To(Equal("lo=65"))
+	})
+
+	It("should encode", func() {
+		Expect(status.Encode(map[string]uint64{"en": 1, "mode": 2, "cnt": 5})).
+			To(Equal([]byte{0xA5}))
+		Expect(header.Encode(map[string]uint64{"ver": 2, "type": 3,
+			"len": 291, "seq": 127})).
+			To(Equal([]byte{0x83, 0x01, 0x23, 0x7F}))
+
+		v, ok := status.Fields[1].LabelValue("AUTO")
+		Expect(ok).To(BeTrue())
+		Expect(v).To(Equal(uint64(2)))
+		_, ok = status.Fields[1].LabelValue("ON")
+		Expect(ok).To(BeFalse())
+
+		b := []byte{0xFF}
+		Expect(status.EncodeInto(b, map[string]uint64{"mode": 0})).To(Succeed())
+		Expect(b).To(Equal([]byte{0x8F}))
+	})
+
+	DescribeTable("invalid",
+		func(l BitLayout, b []byte, vals map[string]uint64, x string) {
+			var err error
+			if vals == nil {
+				_, err = l.Decode(b)
+			} else {
+				err = l.EncodeInto(b, vals)
+			}
+			Expect(err).To(MatchError(x))
+		},
+		Entry("length", status, []byte{1, 2}, nil,
+			"invalid length: 2, expecting 1"),
+		Entry("width", BitLayout{Fields: []BitField{{Name: "a"}}},
+			[]byte{1}, nil, `invalid bit field "a" width: 0`),
+		Entry("outside", BitLayout{Fields: []BitField{
+			{Name: "a", Offset: 4, Width: 5}}},
+			[]byte{1}, nil, `bit field "a" outside 8 bits`),
+		Entry("overlap", BitLayout{Fields: []BitField{
+			{Name: "a", Offset: 4, Width: 2}, {Name: "b", Offset: 2, Width: 3}}},
+			[]byte{1}, nil, `bit field "b" overlaps "a"`),
+		Entry("unknown", status, []byte{1}, map[string]uint64{"x": 1},
+			`unknown bit field: "x"`),
+		Entry("overflow", status, []byte{1}, map[string]uint64{"mode": 8},
+			`value 8 overflows 3 bits field "mode"`),
+	)
+
+	It("should validate layout", func() {
+		Expect(status.Validate()).To(Succeed())
+		Expect(header.Validate()).To(Succeed())
+		_, err := BitLayout{}.Encode(nil)
+		Expect(err).To(MatchError("invalid layout size: 0"))
+	})
+
+	It("should draw diagram", func() {
+		Expect(status.Diagram([]byte{0xA5})).To(Equal("" +
+			"1 010 0101\n" +
+			"| |   `----- cnt  = 5\n" +
+			"| `--------- mode = 2 (AUTO)\n" +
+			"`----------- en   = 1"))
+
+		Expect(BitLayout{Fields: []BitField{
+			{Name: "flag", Offset: 9, Width: 1},
+			{Name: "id", Offset: 2, Width: 4},
+		}}.Diagram([]byte{0x14, 0x02})).To(Equal("" +
+			"000000 1 000 0101 00\n" +
+			"       |     `-------- id   = 5\n" +
+			"       `-------------- flag = 1"))
+	})
+})
@@ -0,0 +1,220 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Build information injected using linker flags, e.g.
+//     go build -ldflags "-X github.com/hanindo/util/v2.BuildVersion=1.2.3"
+// These values take precedence over the information embedded by Go
+// toolchain. BuildRevision with ``-dirty'' suffix marks a dirty build and
+// BuildTime is RFC 3339 time or Unix epoch seconds.
+var (
+	BuildVersion  string
+	BuildRevision string
+	BuildTime     string
+)
+
+// BuildInfo represents the build information of running binary,
+// handy for ``--version'' flag and health endpoint.
+type BuildInfo struct {
+	// Main module path
+	Path string `json:"path,omitempty"`
+	// Main module version as reported by Go toolchain or BuildVersion,
+	// e.g. ``v1.2.3'' or ``(devel)''
+	Version string `json:"version,omitempty"`
+	// Parsed Version, nil if it is not a semantic version
+	SemVer *SemVer `json:"semver,omitempty"`
+	// VCS revision
+	Revision string `json:"revision,omitempty"`
+	// Whether the working tree has local modifications
+	Dirty bool `json:"dirty,omitempty"`
+	// Build or VCS commit time, nil if it is unknown
+	Time *time.Time `json:"time,omitempty"`
+	// Go version used to build the binary
+	GoVersion string `json:"go_version,omitempty"`
+	// Dependency modules
+	Deps []ModuleVersion `json:"deps,omitempty"`
+}
+
+// ModuleVersion represents a dependency module version.
+type ModuleVersion struct {
+	Path    string         `json:"path"`
+	Version string         `json:"version"`
+	Replace *ModuleVersion `json:"replace,omitempty"`
+}
+
+// ReadBuildInfo returns the build information of running binary.
+func ReadBuildInfo() BuildInfo {
+	bi, _ := debug.ReadBuildInfo()
+	return MakeBuildInfo(bi)
+}
+
+// MakeBuildInfo composes build information from bi and the injected Build*
+// variables. The bi may be nil if the binary is not built with module
+// support.
+func MakeBuildInfo(bi *debug.BuildInfo) BuildInfo {
+	var b BuildInfo
+	var settings map[string]string
+	b.GoVersion, settings = buildSettings(bi)
+	if bi != nil {
+		b.Path = bi.Main.Path
+		b.Version = bi.Main.Version
+		for _, d := range bi.Deps {
+			b.Deps = append(b.Deps, makeModuleVersion(d))
+		}
+	}
+
+	if rev, t, ok := parsePseudoVersion(b.Version); ok {
+		b.Revision, b.Time = rev, &t
+	}
+	if rev := settings["vcs.revision"]; rev != "" {
+		b.Revision = rev
+	}
+	if t, err := time.Parse(time.RFC3339, settings["vcs.time"]); err == nil {
+		b.Time = &t
+	}
+	b.Dirty = settings["vcs.modified"] == "true"
+
+	if BuildVersion != "" {
+		b.Version = BuildVersion
+	}
+	if BuildRevision != "" {
+		b.Revision = strings.TrimSuffix(BuildRevision, "-dirty")
+		b.Dirty = b.Revision != BuildRevision
+	}
+	if t, ok := parseBuildTime(BuildTime); ok {
+		b.Time = &t
+	}
+
+	if s, err := ParseModuleVersion(b.Version); err == nil {
+		b.SemVer = &s
+	}
+	return b
+}
+
+func makeModuleVersion(m *debug.Module) ModuleVersion {
+	mv := ModuleVersion{Path: m.Path, Version: m.Version}
+	if m.Replace != nil {
+		r := makeModuleVersion(m.Replace)
+		mv.Replace = &r
+	}
+	return mv
+}
+
+func parseBuildTime(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+var pseudoVersionRE = regexp.MustCompile(
+	`[-.](\d{14})-([0-9a-f]{12})(?:\+incompatible)?$`)
+
+// parsePseudoVersion returns the revision and commit time of Go
+// pseudo-version such as ``v0.0.0-20211222123456-abcdef123456''.
+func parsePseudoVersion(v string) (string, time.Time, bool) {
+	m := pseudoVersionRE.FindStringSubmatch(v)
+	if m == nil {
+		return "", time.Time{}, false
+	}
+	t, err := time.Parse("20060102150405", m[1])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return m[2], t, true
+}
+
+// ParseModuleVersion parses Go module version such as ``v1.2.3'',
+// ``v2.0.0+incompatible'' or pseudo-version like
+// ``v0.0.0-20211222123456-abcdef123456''. The leading ``v'' is optional.
+func ParseModuleVersion(s string) (SemVer, error) {
+	return ParseSemVer(strings.TrimPrefix(s, "v"))
+}
+
+// IsPseudoVersion reports whether v is Go pseudo-version.
+func IsPseudoVersion(v string) bool {
+	_, _, ok := parsePseudoVersion(v)
+	return ok
+}
+
+// CompactVersion returns the main module version as compact Version.
+func (b BuildInfo) CompactVersion() (Version, error) {
+	if b.SemVer == nil {
+		return Version{}, fmt.Errorf("Invalid version: %q", b.Version)
+	}
+	return b.SemVer.Version()
+}
+
+// Dep returns the version of dependency module path, it returns false if the
+// module is not a dependency. The replacement version is returned if the
+// module is replaced.
+func (b BuildInfo) Dep(path string) (string, bool) {
+	for _, d := range b.Deps {
+		if d.Path == path {
+			if d.Replace != nil {
+				return d.Replace.Version, true
+			}
+			return d.Version, true
+		}
+	}
+	return "", false
+}
+
+// String returns one line build information, e.g.
+//     example.com/app v1.2.3 (abcdef123456, dirty) 2021-12-22T12:34:56Z go1.17.5
+func (b BuildInfo) String() string {
+	var sb strings.Builder
+	sb.WriteString(b.Path)
+	if b.Version != "" {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(b.Version)
+	}
+	if b.Revision != "" {
+		rev := b.Revision
+		if len(rev) > 12 {
+			rev = rev[:12]
+		}
+		if b.Dirty {
+			rev += ", dirty"
+		}
+		fmt.Fprintf(&sb, " (%s)", rev)
+	}
+	if b.Time != nil {
+		sb.WriteByte(' ')
+		sb.WriteString(b.Time.Format(time.RFC3339))
+	}
+	if b.GoVersion != "" {
+		sb.WriteByte(' ')
+		sb.WriteString(b.GoVersion)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Verbose returns String() output followed by the dependency versions,
+// one module per line.
+func (b BuildInfo) Verbose() string {
+	var sb strings.Builder
+	sb.WriteString(b.String())
+	for _, d := range b.Deps {
+		fmt.Fprintf(&sb, "\n  %s %s", d.Path, d.Version)
+		if r := d.Replace; r != nil {
+			fmt.Fprintf(&sb, " => %s %s", r.Path, r.Version)
+		}
+	}
+	return sb.String()
+}
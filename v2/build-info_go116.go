@@ -0,0 +1,15 @@
+//go:build !go1.18
+// +build !go1.18
+
+package util
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// buildSettings returns the Go version and build settings of bi.
+// Build settings are only available since Go 1.18.
+func buildSettings(bi *debug.BuildInfo) (string, map[string]string) {
+	return runtime.Version(), nil
+}
@@ -0,0 +1,22 @@
+//go:build go1.18
+// +build go1.18
+
+package util
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// buildSettings returns the Go version and build settings of bi, the Go
+// version is runtime.Version() if bi is nil.
+func buildSettings(bi *debug.BuildInfo) (string, map[string]string) {
+	if bi == nil {
+		return runtime.Version(), nil
+	}
+	settings := make(map[string]string, len(bi.Settings))
+	for _, s := range bi.Settings {
+		settings[s.Key] = s.Value
+	}
+	return bi.GoVersion, settings
+}
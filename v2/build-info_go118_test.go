@@ -0,0 +1,35 @@
+//go:build go1.18
+// +build go1.18
+
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"runtime/debug"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BuildInfo settings", func() {
+	It("should use VCS settings", func() {
+		b := MakeBuildInfo(&debug.BuildInfo{
+			GoVersion: "go1.18",
+			Main: debug.Module{
+				Path:    "example.com/app",
+				Version: "v0.0.0-20211222123456-abcdef123456",
+			},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "fedcba9876543210"},
+				{Key: "vcs.time", Value: "2022-01-02T03:04:05Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		})
+		Expect(b.GoVersion).To(Equal("go1.18"))
+		Expect(b.Revision).To(Equal("fedcba9876543210"))
+		Expect(*b.Time).
+			To(Equal(time.Date(2022, time.January, 2, 3, 4, 5, 0, time.UTC)))
+		Expect(b.Dirty).To(BeTrue())
+	})
+})
@@ -0,0 +1,126 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BuildInfo", func() {
+	bi := &debug.BuildInfo{
+		Main: debug.Module{
+			Path:    "example.com/app",
+			Version: "v1.2.4-0.20211222123456-abcdef123456",
+		},
+		Deps: []*debug.Module{
+			{Path: "example.com/lib", Version: "v0.3.0"},
+			{Path: "example.com/old", Version: "v1.0.0",
+				Replace: &debug.Module{Path: "../old", Version: "(devel)"}},
+		},
+	}
+
+	AfterEach(func() {
+		BuildVersion, BuildRevision, BuildTime = "", "", ""
+	})
+
+	It("should read running binary", func() {
+		b := ReadBuildInfo()
+		Expect(b.Path).To(Equal("github.com/hanindo/util/v2"))
+		Expect(b.GoVersion).To(HavePrefix("go"))
+	})
+
+	It("should parse pseudo-version", func() {
+		b := MakeBuildInfo(bi)
+		Expect(b.Path).To(Equal("example.com/app"))
+		Expect(b.SemVer.String()).
+			To(Equal("1.2.4-0.20211222123456-abcdef123456"))
+		Expect(b.Revision).To(Equal("abcdef123456"))
+		Expect(*b.Time).
+			To(Equal(time.Date(2021, time.December, 22, 12, 34, 56, 0, time.UTC)))
+		_, err := b.CompactVersion()
+		Expect(err).To(MatchError("Unsupported pre-release: " +
+			"1.2.4-0.20211222123456-abcdef123456"))
+		v, ok := b.Dep("example.com/lib")
+		Expect(v).To(Equal("v0.3.0"))
+		Expect(ok).To(BeTrue())
+		v, _ = b.Dep("example.com/old")
+		Expect(v).To(Equal("(devel)"))
+		_, ok = b.Dep("example.com/none")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should use injected values", func() {
+		BuildVersion = "v1.2.3"
+		BuildRevision = "0123456789abcdef0123-dirty"
+		BuildTime = "1640176496"
+		b := MakeBuildInfo(bi)
+		b.GoVersion = "go1.17.5"
+		Expect(b.CompactVersion()).To(Equal(MakeVersion(1, 2, 3)))
+		Expect(b.Revision).To(Equal("0123456789abcdef0123"))
+		Expect(b.Dirty).To(BeTrue())
+		Expect(b.String()).To(Equal("example.com/app v1.2.3 " +
+			"(0123456789ab, dirty) 2021-12-22T12:34:56Z go1.17.5"))
+		Expect(b.Verbose()).To(Equal(b.String() + `
+  example.com/lib v0.3.0
+  example.com/old v1.0.0 => ../old (devel)`))
+	})
+
+	It("should be encoded as JSON", func() {
+		BuildVersion = "v1.2.3"
+		BuildRevision = "abc"
+		BuildTime = "2021-12-22T12:34:56Z"
+		b := MakeBuildInfo(&debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app"},
+		})
+		b.GoVersion = "go1.17.5"
+		Expect(json.Marshal(b)).To(MatchJSON(`{
+			"path": "example.com/app",
+			"version": "v1.2.3",
+			"semver": "1.2.3",
+			"revision": "abc",
+			"time": "2021-12-22T12:34:56Z",
+			"go_version": "go1.17.5"
+		}`))
+	})
+
+	It("should omit unknown time", func() {
+		b := MakeBuildInfo(nil)
+		Expect(b.Time).To(BeNil())
+		Expect(b.GoVersion).To(Equal(runtime.Version()))
+		Expect(b.String()).To(Equal(runtime.Version()))
+		Expect(json.Marshal(b)).
+			To(MatchJSON(`{"go_version":"` + runtime.Version() + `"}`))
+	})
+
+	It("should handle devel version", func() {
+		b := MakeBuildInfo(&debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+		})
+		Expect(b.SemVer).To(BeNil())
+		_, err := b.CompactVersion()
+		Expect(err).To(MatchError(`Invalid version: "(devel)"`))
+	})
+
+	DescribeTable("ParseModuleVersion",
+		func(s, x string, pseudo bool) {
+			v, err := ParseModuleVersion(s)
+			Expect(err).To(Succeed())
+			Expect(v.String()).To(Equal(x))
+			Expect(IsPseudoVersion(s)).To(Equal(pseudo))
+		},
+		Entry("release", "v1.2.3", "1.2.3", false),
+		Entry("no prefix", "1.2.3", "1.2.3", false),
+		Entry("incompatible", "v2.0.0+incompatible", "2.0.0+incompatible",
+			false),
+		Entry("pseudo", "v0.0.0-20211222123456-abcdef123456",
+			"0.0.0-20211222123456-abcdef123456", true),
+		Entry("pseudo pre-release", "v1.2.3-rc.1.0.20211222123456-abcdef123456",
+			"1.2.3-rc.1.0.20211222123456-abcdef123456", true),
+	)
+})
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	siByteUnits  = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	iecByteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+)
+
+// formatBytes returns n bytes in the largest unit of base multiples, rounded
+// to prec decimal places without trailing zeroes.
+func formatBytes(n int64, prec int, base float64, units []string) string {
+	x := float64(n)
+	if math.Abs(x) < base {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	if prec < 0 {
+		prec = 0
+	}
+	i := 0
+	for i < len(units)-1 && math.Abs(x) >= base {
+		x /= base
+		i++
+	}
+	v := Round(x, prec)
+	if math.Abs(v) >= base && i < len(units)-1 {
+		v = Round(x/base, prec)
+		i++
+	}
+	return TrimZero(strconv.FormatFloat(v, 'f', prec, 64)) + " " + units[i]
+}
+
+// FormatBytes returns n bytes in SI unit of 1000 multiples, rounded to prec
+// decimal places without trailing zeroes, like ``3.2 MB'' or ``999 B''.
+func FormatBytes(n int64, prec int) string {
+	return formatBytes(n, prec, 1000, siByteUnits)
+}
+
+// FormatIBytes returns n bytes in IEC unit of 1024 multiples, rounded to
+// prec decimal places without trailing zeroes, like ``1.5 KiB''.
+func FormatIBytes(n int64, prec int) string {
+	return formatBytes(n, prec, 1024, iecByteUnits)
+}
+
+var byteSizeRE = regexp.MustCompile(
+	`^([-+]?(?:\d+(?:\.\d*)?|\.\d+))\s*([kKmMgGtTpPeE]?)(i?)[bB]?$`)
+
+// ParseBytes parses byte size with case insensitive SI or IEC unit, like
+// ``3.2 MB'', ``1.5KiB'', ``10k'' or ``512''. The unit without ``i'' is SI,
+// so ``1KB'' is 1000 bytes. Fractional bytes are rounded half away from
+// zero.
+func ParseBytes(s string) (int64, error) {
+	m := byteSizeRE.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil || m[3] != "" && m[2] == "" {
+		return 0, fmt.Errorf("invalid byte size: %q", s)
+	}
+	n, err := ParseDecimal(m[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size: %q", s)
+	}
+	base := int64(1000)
+	if m[3] != "" {
+		base = 1024
+	}
+	if m[2] != "" {
+		exp := strings.Index("kmgtpe", strings.ToLower(m[2])) + 1
+		for i := 0; i < exp; i++ {
+			n = n.Mul(MakeDecimal(base, 0))
+		}
+	}
+	b, ok := decimalInt64(n)
+	if !ok {
+		return 0, fmt.Errorf("byte size out of range: %q", s)
+	}
+	return b, nil
+}
@@ -0,0 +1,69 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ByteSize", func() {
+	DescribeTable("FormatBytes", func(n int64, prec int, x string) {
+		Expect(FormatBytes(n, prec)).To(Equal(x))
+	},
+		Entry("bytes", int64(999), 1, "999 B"),
+		Entry("kilo", int64(1000), 1, "1 kB"),
+		Entry("mega", int64(3210000), 1, "3.2 MB"),
+		Entry("precision", int64(3216000), 2, "3.22 MB"),
+		Entry("no precision", int64(3216000), 0, "3 MB"),
+		Entry("negative precision", int64(3616000), -1, "4 MB"),
+		Entry("carry", int64(999999), 1, "1 MB"),
+		Entry("negative", int64(-1500), 1, "-1.5 kB"),
+		Entry("max", int64(math.MaxInt64), 2, "9.22 EB"),
+	)
+
+	DescribeTable("FormatIBytes", func(n int64, prec int, x string) {
+		Expect(FormatIBytes(n, prec)).To(Equal(x))
+	},
+		Entry("bytes", int64(1023), 1, "1023 B"),
+		Entry("kibi", int64(1536), 1, "1.5 KiB"),
+		Entry("mebi", int64(5*1024*1024), 1, "5 MiB"),
+		Entry("carry", int64(1024*1024-1), 2, "1 MiB"),
+		Entry("max", int64(math.MaxInt64), 1, "8 EiB"),
+	)
+
+	DescribeTable("ParseBytes", func(s string, x int64) {
+		Expect(ParseBytes(s)).To(Equal(x))
+	},
+		Entry("bytes", "512", int64(512)),
+		Entry("bytes unit", "512 B", int64(512)),
+		Entry("SI", "3.2 MB", int64(3200000)),
+		Entry("SI lower", "10kb", int64(10000)),
+		Entry("SI short", "10k", int64(10000)),
+		Entry("IEC", "1.5KiB", int64(1536)),
+		Entry("IEC short", "2Gi", int64(2<<30)),
+		Entry("round", "1.0005 kB", int64(1001)),
+		Entry("negative", "-1 KiB", int64(-1024)),
+		Entry("max", "9223372036854775807", int64(math.MaxInt64)),
+	)
+
+	DescribeTable("ParseBytes error", func(s, x string) {
+		_, err := ParseBytes(s)
+		Expect(err).To(MatchError(x))
+	},
+		Entry("empty", "", `invalid byte size: ""`),
+		Entry("unit only", "MB", `invalid byte size: "MB"`),
+		Entry("unknown unit", "1 XB", `invalid byte size: "1 XB"`),
+		Entry("i only", "1iB", `invalid byte size: "1iB"`),
+		Entry("overflow", "9.3 EB", `byte size out of range: "9.3 EB"`),
+		Entry("IEC overflow", "8 EiB", `byte size out of range: "8 EiB"`),
+	)
+
+	It("should roundtrip", func() {
+		for _, n := range []int64{0, 1, 1500, 3200000, 1250000000000} {
+			Expect(ParseBytes(FormatBytes(n, 3))).To(Equal(n))
+		}
+	})
+})
@@ -0,0 +1,427 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CALVER_SCHEME is the scheme used by CalVer.UnmarshalText when the version
+// doesn't have a scheme yet, use CalScheme.Zero to decode other schemes.
+const CALVER_SCHEME = "YYYY.MM.MICRO"
+
+// CalScheme represents Calendar Versioning https://calver.org scheme, such
+// as ``YYYY.MM.MICRO'' or ``YY.0M.DD''. The scheme is dot separated tokens:
+//     YYYY    full year, e.g. 2006
+//     YY      short year since 2000, e.g. 6, 16, 106
+//     0Y      zero padded short year, e.g. 06, 16, 106
+//     MM, 0M  month, e.g. 1 or 01
+//     WW, 0W  ISO week of the ISO year, e.g. 1 or 01
+//     DD, 0D  day of month, e.g. 2 or 02
+//     MAJOR, MINOR, MICRO
+//             counters incremented for releases in the same period
+// The scheme must have one year token, and each token may appear only once.
+// Week can't be combined with month or day, and day needs month.
+type CalScheme struct {
+	text   string
+	tokens []string
+}
+
+var calTokens = map[string]bool{
+	"YYYY": true, "YY": true, "0Y": true,
+	"MM": true, "0M": true, "WW": true, "0W": true, "DD": true, "0D": true,
+	"MAJOR": true, "MINOR": true, "MICRO": true,
+}
+
+// calKind returns the kind of token, the padded variants are the same kind.
+func calKind(t string) string {
+	switch t {
+	case "YYYY", "YY", "0Y":
+		return "Y"
+	case "MM", "0M":
+		return "M"
+	case "WW", "0W":
+		return "W"
+	case "DD", "0D":
+		return "D"
+	}
+	return t
+}
+
+// ParseCalScheme parses s as calendar versioning scheme.
+func ParseCalScheme(s string) (CalScheme, error) {
+	cs := CalScheme{text: s, tokens: strings.Split(s, ".")}
+	seen := make(map[string]bool)
+	for _, t := range cs.tokens {
+		if !calTokens[t] {
+			return CalScheme{}, fmt.Errorf("Invalid calver token: %q", t)
+		}
+		k := calKind(t)
+		if seen[k] {
+			return CalScheme{}, fmt.Errorf("Duplicate calver token: %q", t)
+		}
+		seen[k] = true
+	}
+	switch {
+	case !seen["Y"]:
+		return CalScheme{}, fmt.Errorf("Invalid calver scheme %q: no year", s)
+	case seen["W"] && (seen["M"] || seen["D"]):
+		return CalScheme{}, fmt.Errorf("Invalid calver scheme %q: "+
+			"week with month or day", s)
+	case seen["D"] && !seen["M"]:
+		return CalScheme{}, fmt.Errorf("Invalid calver scheme %q: "+
+			"day without month", s)
+	}
+	return cs, nil
+}
+
+// String returns the scheme text.
+func (cs CalScheme) String() string {
+	return cs.text
+}
+
+// has reports whether the scheme has token of kind k.
+func (cs CalScheme) has(k string) bool {
+	for _, t := range cs.tokens {
+		if calKind(t) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Zero returns the zero version of cs scheme, which can be the target of
+// CalVer.UnmarshalText to decode version in cs scheme.
+func (cs CalScheme) Zero() CalVer {
+	return CalVer{scheme: cs}
+}
+
+// ForDate returns the first version released on d date, the counters are
+// zero.
+func (cs CalScheme) ForDate(d Date) CalVer {
+	c := CalVer{scheme: cs}
+	y, m, day := d.tm.Date()
+	if cs.has("W") {
+		y, c.week = d.tm.ISOWeek()
+	}
+	c.year = y
+	if cs.has("M") {
+		c.month = int(m)
+	}
+	if cs.has("D") {
+		c.day = day
+	}
+	return c
+}
+
+// Today returns the first version released today according to c clock,
+// in the clock's time zone.
+func (cs CalScheme) Today(c Clock) CalVer {
+	return cs.ForDate(MakeDate(c.Now()))
+}
+
+// Parse parses s as calendar version in cs scheme.
+func (cs CalScheme) Parse(s string) (CalVer, error) {
+	c := CalVer{scheme: cs}
+	parts := strings.Split(s, ".")
+	if len(cs.tokens) == 0 || len(parts) != len(cs.tokens) {
+		return c, fmt.Errorf("Invalid text for %T: %q", &c, s)
+	}
+
+	for i, t := range cs.tokens {
+		p := parts[i]
+		n, err := strconv.Atoi(p)
+		if !isNumeric(p) || err != nil {
+			return c, fmt.Errorf("Invalid %s in %q: %s", t, s, p)
+		}
+		if t[0] == '0' {
+			if len(p) < 2 || (len(p) > 2 && p[0] == '0') {
+				return c, fmt.Errorf("Invalid %s in %q: %s", t, s, p)
+			}
+		} else if len(p) > 1 && p[0] == '0' {
+			return c, fmt.Errorf("Invalid %s in %q: %s", t, s, p)
+		}
+		c.set(t, n)
+	}
+
+	if err := c.validate(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// ParseCalVer parses s as calendar version in scheme.
+func ParseCalVer(scheme, s string) (CalVer, error) {
+	cs, err := ParseCalScheme(scheme)
+	if err != nil {
+		return CalVer{}, err
+	}
+	return cs.Parse(s)
+}
+
+// FromVersion converts v to calendar version, the scheme must have exactly
+// three tokens.
+func (cs CalScheme) FromVersion(v Version) (CalVer, error) {
+	c := CalVer{scheme: cs}
+	if len(cs.tokens) != 3 {
+		return c, fmt.Errorf("Incompatible calver scheme %q for %T",
+			cs.text, v)
+	}
+	for i, n := range []uint8{v.Major, v.Minor, v.Patch} {
+		c.set(cs.tokens[i], int(n))
+	}
+	if err := c.validate(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+//============================================================================
+
+// CalVer represents a calendar version in a CalScheme, e.g. ``2021.02.3''
+// in ``YYYY.0M.MICRO'' scheme.
+//
+// Versions are compared by their tokens in the scheme order, so comparing
+// versions of different schemes is meaningless.
+type CalVer struct {
+	scheme CalScheme
+	year   int
+	month  int
+	week   int
+	day    int
+	major  int
+	minor  int
+	micro  int
+}
+
+// field returns pointer to the component of token t.
+func (c *CalVer) field(t string) *int {
+	switch calKind(t) {
+	case "Y":
+		return &c.year
+	case "M":
+		return &c.month
+	case "W":
+		return &c.week
+	case "D":
+		return &c.day
+	case "MAJOR":
+		return &c.major
+	case "MINOR":
+		return &c.minor
+	}
+	return &c.micro
+}
+
+// set sets the component of token t from its numeric text value.
+func (c *CalVer) set(t string, n int) {
+	if t == "YY" || t == "0Y" {
+		n += 2000
+	}
+	*c.field(t) = n
+}
+
+// validate checks the calendar components.
+func (c CalVer) validate() error {
+	if c.scheme.has("M") && (c.month < 1 || c.month > 12) {
+		return fmt.Errorf("Invalid month: %d", c.month)
+	}
+	if c.scheme.has("W") && (c.week < 1 || c.week > WeeksInYear(c.year)) {
+		return fmt.Errorf("Invalid week of %d: %d", c.year, c.week)
+	}
+	if c.scheme.has("D") {
+		t := time.Date(c.year, time.Month(c.month), c.day, 0, 0, 0, 0,
+			time.UTC)
+		if c.day < 1 || t.Day() != c.day {
+			return fmt.Errorf("Invalid day of %04d-%02d: %d",
+				c.year, c.month, c.day)
+		}
+	}
+	return nil
+}
+
+// Scheme returns the scheme of c.
+func (c CalVer) Scheme() CalScheme {
+	return c.scheme
+}
+
+// Year returns the full year of c, or ISO year for week based scheme.
+func (c CalVer) Year() int {
+	return c.year
+}
+
+// Month returns the month of c, or zero if the scheme has no month.
+func (c CalVer) Month() time.Month {
+	return time.Month(c.month)
+}
+
+// Week returns the ISO week of c, or zero if the scheme has no week.
+func (c CalVer) Week() int {
+	return c.week
+}
+
+// Day returns the day of month of c, or zero if the scheme has no day.
+func (c CalVer) Day() int {
+	return c.day
+}
+
+// Major returns the MAJOR counter of c.
+func (c CalVer) Major() int {
+	return c.major
+}
+
+// Minor returns the MINOR counter of c.
+func (c CalVer) Minor() int {
+	return c.minor
+}
+
+// Micro returns the MICRO counter of c.
+func (c CalVer) Micro() int {
+	return c.micro
+}
+
+// Date returns the release date of c in tz time zone. Missing components
+// are the start of the period, so ``2021.02'' is 2021-02-01 and week based
+// version is the Monday of the week.
+func (c CalVer) Date(tz TZ) Date {
+	if c.scheme.has("W") {
+		yw := YearWeek{year: c.year, week: c.week, tz: tz}
+		return yw.First()
+	}
+	m, d := c.month, c.day
+	if m == 0 {
+		m = 1
+	}
+	if d == 0 {
+		d = 1
+	}
+	return makeDay(c.year, time.Month(m), d, tz)
+}
+
+// Next returns the next version released today according to clk clock.
+// The version of today is returned if it is after c, otherwise the last
+// counter of c is incremented and the following counters are reset.
+// It returns error if the scheme has no counter for another release.
+func (c CalVer) Next(clk Clock) (CalVer, error) {
+	n := c.scheme.Today(clk)
+	if n.Compare(c) > 0 {
+		return n, nil
+	}
+
+	n = c
+	last := ""
+	for _, t := range c.scheme.tokens {
+		switch t {
+		case "MAJOR", "MINOR", "MICRO":
+			last = t
+		}
+	}
+	switch last {
+	case "":
+		return c, fmt.Errorf("No counter in calver scheme %q "+
+			"for release after %s", c.scheme, c)
+	case "MAJOR":
+		n.major++
+		n.minor, n.micro = 0, 0
+	case "MINOR":
+		n.minor++
+		n.micro = 0
+	default:
+		n.micro++
+	}
+	return n, nil
+}
+
+// Compare returns -1, 0 or +1 if c is before, equal or after o, comparing
+// the tokens in c scheme order.
+func (c CalVer) Compare(o CalVer) int {
+	for _, t := range c.scheme.tokens {
+		if r := cmpInt(*c.field(t), *o.field(t)); r != 0 {
+			return r
+		}
+	}
+	return 0
+}
+
+// Before reports whether c is before o.
+func (c CalVer) Before(o CalVer) bool {
+	return c.Compare(o) < 0
+}
+
+// After reports whether c is after o.
+func (c CalVer) After(o CalVer) bool {
+	return c.Compare(o) > 0
+}
+
+// Equal reports whether c and o are the same version.
+func (c CalVer) Equal(o CalVer) bool {
+	return c.Compare(o) == 0
+}
+
+// Version converts c to compact Version, the scheme must have exactly three
+// tokens and every component must fit in uint8, e.g. ``YY.0M.MICRO'' up to
+// year 2255.
+func (c CalVer) Version() (Version, error) {
+	if len(c.scheme.tokens) != 3 {
+		return Version{}, fmt.Errorf("Incompatible calver scheme %q for %T",
+			c.scheme.text, Version{})
+	}
+	var n [3]uint8
+	for i, t := range c.scheme.tokens {
+		v := *c.field(t)
+		if t == "YYYY" {
+			return Version{}, fmt.Errorf("Component outside range 0-255: %s",
+				c)
+		} else if t == "YY" || t == "0Y" {
+			v -= 2000
+		}
+		if v < 0 || v > 255 {
+			return Version{}, fmt.Errorf("Component outside range 0-255: %s",
+				c)
+		}
+		n[i] = uint8(v)
+	}
+	return MakeVersion(n[0], n[1], n[2]), nil
+}
+
+// String returns the version formatted in its scheme.
+func (c CalVer) String() string {
+	ss := make([]string, len(c.scheme.tokens))
+	for i, t := range c.scheme.tokens {
+		n := *c.field(t)
+		if t == "YY" || t == "0Y" {
+			n -= 2000
+		}
+		if t[0] == '0' {
+			ss[i] = fmt.Sprintf("%02d", n)
+		} else {
+			ss[i] = strconv.Itoa(n)
+		}
+	}
+	return strings.Join(ss, ".")
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (c CalVer) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text is parsed using c scheme, or CALVER_SCHEME if c has no scheme.
+func (c *CalVer) UnmarshalText(b []byte) error {
+	cs := c.scheme
+	if len(cs.tokens) == 0 {
+		var err error
+		if cs, err = ParseCalScheme(CALVER_SCHEME); err != nil {
+			return err
+		}
+	}
+	v, err := cs.Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
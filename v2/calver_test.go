@@ -0,0 +1,175 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CalVer", func() {
+	scheme := func(s string) CalScheme {
+		cs, err := ParseCalScheme(s)
+		Expect(err).To(Succeed(), s)
+		return cs
+	}
+
+	parse := func(s, v string) CalVer {
+		c, err := ParseCalVer(s, v)
+		Expect(err).To(Succeed(), v)
+		return c
+	}
+
+	DescribeTable("ParseCalScheme error",
+		func(s, x string) {
+			_, err := ParseCalScheme(s)
+			Expect(err).To(MatchError(x))
+		},
+		Entry("token", "YYYY.M", `Invalid calver token: "M"`),
+		Entry("empty", "", `Invalid calver token: ""`),
+		Entry("duplicate", "YYYY.MM.YY", `Duplicate calver token: "YY"`),
+		Entry("no year", "MAJOR.MM",
+			`Invalid calver scheme "MAJOR.MM": no year`),
+		Entry("week", "YYYY.WW.DD",
+			`Invalid calver scheme "YYYY.WW.DD": week with month or day`),
+		Entry("day", "YYYY.DD",
+			`Invalid calver scheme "YYYY.DD": day without month`),
+	)
+
+	DescribeTable("Parse",
+		func(s, v string, y int, m time.Month, w, d, micro int, date string) {
+			c := parse(s, v)
+			Expect(c.Year()).To(Equal(y))
+			Expect(c.Month()).To(Equal(m))
+			Expect(c.Week()).To(Equal(w))
+			Expect(c.Day()).To(Equal(d))
+			Expect(c.Micro()).To(Equal(micro))
+			Expect(c.String()).To(Equal(v))
+			Expect(c.Scheme().String()).To(Equal(s))
+			Expect(c.Date(7 * 60).String()).To(Equal(date))
+		},
+		Entry("year month micro", "YYYY.MM.MICRO", "2021.2.3",
+			2021, time.February, 0, 0, 3, "2021-02-01 +07:00"),
+		Entry("padded", "YY.0M.0D", "21.02.09",
+			2021, time.February, 0, 9, 0, "2021-02-09 +07:00"),
+		Entry("short year", "YY.0M.DD", "6.12.31",
+			2006, time.December, 0, 31, 0, "2006-12-31 +07:00"),
+		Entry("padded short year", "0Y.MM", "106.1",
+			2106, time.January, 0, 0, 0, "2106-01-01 +07:00"),
+		Entry("week", "YYYY.0W.MICRO", "2021.01.0",
+			2021, time.Month(0), 1, 0, 0, "2021-01-04 +07:00"),
+		Entry("year", "YYYY.MICRO", "2021.15",
+			2021, time.Month(0), 0, 0, 15, "2021-01-01 +07:00"),
+	)
+
+	DescribeTable("Parse error",
+		func(s, v, x string) {
+			_, err := ParseCalVer(s, v)
+			Expect(err).To(MatchError(x))
+		},
+		Entry("length", "YYYY.MM.MICRO", "2021.2",
+			`Invalid text for *util.CalVer: "2021.2"`),
+		Entry("number", "YYYY.MM.MICRO", "2021.2.x",
+			`Invalid MICRO in "2021.2.x": x`),
+		Entry("sign", "YYYY.MM", "2021.+2", `Invalid MM in "2021.+2": +2`),
+		Entry("leading zero", "YYYY.MM", "2021.02",
+			`Invalid MM in "2021.02": 02`),
+		Entry("no padding", "YYYY.0M", "2021.2", `Invalid 0M in "2021.2": 2`),
+		Entry("month", "YYYY.MM", "2021.13", "Invalid month: 13"),
+		Entry("day", "YY.0M.0D", "21.02.29", "Invalid day of 2021-02: 29"),
+		Entry("week", "YYYY.WW", "2021.53", "Invalid week of 2021: 53"),
+		Entry("scheme", "YYYY.M", "2021.1", `Invalid calver token: "M"`),
+	)
+
+	It("should compare", func() {
+		a := parse("YYYY.MM.MICRO", "2021.2.10")
+		b := parse("YYYY.MM.MICRO", "2021.10.0")
+		Expect(a.Compare(b)).To(Equal(-1))
+		Expect(b.Compare(a)).To(Equal(1))
+		Expect(a.Before(b)).To(BeTrue())
+		Expect(b.After(a)).To(BeTrue())
+		Expect(a.Equal(parse("YYYY.MM.MICRO", "2021.2.10"))).To(BeTrue())
+	})
+
+	DescribeTable("Next",
+		func(s, v, now, x string) {
+			t, err := time.Parse(time.RFC3339, now)
+			Expect(err).To(Succeed())
+			n, err := parse(s, v).Next(NewMockClock(t))
+			Expect(err).To(Succeed())
+			Expect(n.String()).To(Equal(x))
+		},
+		Entry("new month", "YYYY.MM.MICRO", "2021.2.3",
+			"2021-03-01T10:00:00Z", "2021.3.0"),
+		Entry("same month", "YYYY.MM.MICRO", "2021.3.0",
+			"2021-03-31T10:00:00Z", "2021.3.1"),
+		Entry("clock zone", "YY.0M.0D.MICRO", "21.03.01.0",
+			"2021-03-01T20:00:00-05:00", "21.03.01.1"),
+		Entry("clock behind", "YY.0M.0D.MICRO", "21.03.02.4",
+			"2021-03-01T20:00:00Z", "21.03.02.5"),
+		Entry("last counter", "YYYY.MINOR.MICRO", "2021.1.5",
+			"2021-12-31T00:00:00Z", "2021.1.6"),
+		Entry("minor", "YYYY.MINOR", "2021.1",
+			"2021-12-31T00:00:00Z", "2021.2"),
+		Entry("major", "MAJOR.YYYY", "1.2021",
+			"2021-12-31T00:00:00Z", "2.2021"),
+		Entry("iso week", "YYYY.0W", "2020.52",
+			"2021-01-01T00:00:00Z", "2020.53"),
+	)
+
+	It("should fail next without counter", func() {
+		clk := NewMockClock(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+		c := parse("YY.0M.0D", "21.03.01")
+		_, err := c.Next(clk)
+		Expect(err).To(MatchError(`No counter in calver scheme "YY.0M.0D" ` +
+			`for release after 21.03.01`))
+
+		n := scheme("YY.0M.0D").Today(clk)
+		Expect(n.Equal(c)).To(BeTrue())
+	})
+
+	It("should convert to/from Version", func() {
+		cs := scheme("YY.0M.MICRO")
+		c, err := cs.FromVersion(MakeVersion(21, 2, 3))
+		Expect(err).To(Succeed())
+		Expect(c.String()).To(Equal("21.02.3"))
+		Expect(c.Version()).To(Equal(MakeVersion(21, 2, 3)))
+
+		_, err = cs.FromVersion(MakeVersion(21, 13, 3))
+		Expect(err).To(MatchError("Invalid month: 13"))
+
+		_, err = scheme("YYYY.MM").FromVersion(MakeVersion(21, 2, 3))
+		Expect(err).To(MatchError(
+			`Incompatible calver scheme "YYYY.MM" for util.Version`))
+
+		_, err = parse("YYYY.MM.MICRO", "2021.2.3").Version()
+		Expect(err).To(MatchError("Component outside range 0-255: 2021.2.3"))
+		_, err = parse("YY.MM.MICRO", "21.2.300").Version()
+		Expect(err).To(MatchError("Component outside range 0-255: 21.2.300"))
+	})
+
+	It("should be encoded as text", func() {
+		var s struct {
+			V CalVer
+		}
+		Expect(json.Unmarshal([]byte(`{"V":"2021.2.3"}`), &s)).To(Succeed())
+		Expect(s.V.Micro()).To(Equal(3))
+		Expect(json.Marshal(s)).To(MatchJSON(`{"V":"2021.2.3"}`))
+
+		c := parse("YY.0M.0D", "21.02.09")
+		Expect(c.UnmarshalText([]byte("21.12.31"))).To(Succeed())
+		Expect(c.String()).To(Equal("21.12.31"))
+		Expect(c.UnmarshalText([]byte("21.2.28"))).To(MatchError(
+			`Invalid 0M in "21.2.28": 2`))
+
+		cs, err := ParseCalScheme("YY.0M.DD")
+		Expect(err).To(Succeed())
+		z := cs.Zero()
+		Expect(z.Scheme()).To(Equal(cs))
+		Expect(z.UnmarshalText([]byte("21.02.9"))).To(Succeed())
+		Expect(z.Day()).To(Equal(9))
+	})
+})
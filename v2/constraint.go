@@ -0,0 +1,349 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Constraint represents version constraint expression such as
+//     >=1.2.0 <2.0.0 || ^3.1
+//
+// Comparators separated by spaces (or commas) must all be satisfied, and
+// comparator sets separated by ``||'' are alternatives. Supported
+// comparators are:
+//     =1.2.3 !=1.2.3 >1.2.3 >=1.2.3 <1.2.3 <=1.2.3
+//     1.2.3        same as =1.2.3
+//     1.2, 1.2.x   >=1.2.0 <1.3.0, also for 1, 1.x, and * for any version
+//     ^1.2.3       >=1.2.3 <2.0.0, or <0.3.0 for ^0.2.3, <0.0.4 for ^0.0.3
+//     ~1.2.3       >=1.2.3 <1.3.0, ~1 is >=1.0.0 <2.0.0
+//     1.2 - 2.3    >=1.2.0 <2.4.0, partial upper bound includes the whole
+//                  minor or major version
+// Operators other than ``!='' accept partial versions, missing components
+// are treated as wildcards. Pre-release versions are compared by their
+// SemVer precedence, the generated upper bounds above and ``<'' without
+// pre-release are actually ``<2.0.0-0'' and so on, so the pre-releases of
+// the excluded version like 2.0.0-alpha satisfy neither ^1.2.3 nor <2.0.0.
+type Constraint struct {
+	text string
+	sets [][]comparator
+}
+
+type comparator struct {
+	op string
+	v  SemVer
+}
+
+// ParseConstraint parses s as version constraint.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{text: strings.TrimSpace(s)}
+	for _, alt := range strings.Split(s, "||") {
+		set, err := parseComparatorSet(alt)
+		if err != nil {
+			return c, err
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+func parseComparatorSet(s string) ([]comparator, error) {
+	fs := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	if len(fs) == 0 {
+		return []comparator{}, nil
+	}
+
+	// Hyphen range
+	if len(fs) == 3 && fs[1] == "-" {
+		lo, _, err := parsePartial(fs[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, n, err := parsePartial(fs[2])
+		if err != nil {
+			return nil, err
+		}
+		set := []comparator{{">=", lo}}
+		switch n {
+		case 0:
+		case 3:
+			set = append(set, comparator{"<=", hi})
+		default:
+			set = append(set, comparator{"<", upperPartial(hi, n)})
+		}
+		return set, nil
+	}
+
+	var set []comparator
+	for i := 0; i < len(fs); i++ {
+		f := fs[i]
+		// Allow space between operator and version like ``>= 1.2''
+		if strings.Trim(f, "=!<>^~") == "" && i+1 < len(fs) {
+			i++
+			f += fs[i]
+		}
+		cs, err := parseComparator(f)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cs...)
+	}
+	return set, nil
+}
+
+func parseComparator(s string) ([]comparator, error) {
+	rest := strings.TrimLeft(s, "=!<>^~")
+	op := s[:len(s)-len(rest)]
+	v, n, err := parsePartial(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "", "=":
+		if n == 3 {
+			return []comparator{{"=", v}}, nil
+		} else if n == 0 {
+			return []comparator{}, nil
+		}
+		return []comparator{{">=", v}, {"<", upperPartial(v, n)}}, nil
+	case "!=":
+		if n != 3 {
+			return nil, fmt.Errorf("Invalid constraint %q: "+
+				"!= needs full version", s)
+		}
+		return []comparator{{"!=", v}}, nil
+	case ">":
+		if n == 3 {
+			return []comparator{{">", v}}, nil
+		} else if n == 0 {
+			return []comparator{{"<", upperPartial(SemVer{}, 0)}}, nil
+		}
+		return []comparator{{">=", bumpPartial(v, n)}}, nil
+	case ">=":
+		return []comparator{{">=", v}}, nil
+	case "<":
+		if len(v.Pre) == 0 {
+			v = upperPartial(v, 0)
+		}
+		return []comparator{{"<", v}}, nil
+	case "<=":
+		if n == 3 {
+			return []comparator{{"<=", v}}, nil
+		} else if n == 0 {
+			return []comparator{}, nil
+		}
+		return []comparator{{"<", upperPartial(v, n)}}, nil
+	case "^":
+		if n == 0 {
+			return []comparator{}, nil
+		}
+		// Bump the first non zero component, or the last specified one.
+		i := 0
+		for i < n-1 && ((i == 0 && v.Major == 0) ||
+			(i == 1 && v.Minor == 0)) {
+			i++
+		}
+		return []comparator{{">=", v}, {"<", upperPartial(v, i+1)}}, nil
+	case "~":
+		if n == 0 {
+			return []comparator{}, nil
+		} else if n == 1 {
+			return []comparator{{">=", v}, {"<", upperPartial(v, 1)}}, nil
+		}
+		return []comparator{{">=", v}, {"<", upperPartial(v, 2)}}, nil
+	}
+	return nil, fmt.Errorf("Invalid constraint operator: %q", s)
+}
+
+// parsePartial parses partial version such as ``1'', ``1.2'', ``1.x'' or
+// ``*''. It returns the version with zero missing components, and the
+// number of specified components.
+func parsePartial(s string) (SemVer, int, error) {
+	var v SemVer
+	if s == "" {
+		return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+	}
+
+	core := s
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+	}
+
+	n := 0
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			break
+		}
+		if n != i {
+			return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+		}
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+		}
+		u, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+		}
+		*nums[i] = u
+		n++
+	}
+	for _, p := range parts[n:] {
+		if p != "x" && p != "X" && p != "*" {
+			return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+		}
+	}
+
+	if core != s {
+		if n != 3 {
+			return v, 0, fmt.Errorf("Invalid constraint version: %q", s)
+		}
+		sv, err := ParseSemVer(s)
+		if err != nil {
+			return v, 0, err
+		}
+		v.Pre = sv.Pre
+	}
+	return v, n, nil
+}
+
+// upperPartial returns the exclusive upper bound of partial version v with
+// n components, like bumpPartial with the lowest pre-release ``-0'', so
+// ``<2.0.0-0'' excludes 2.0.0-alpha too. Zero n doesn't bump v, 0.0.0-0 is
+// below any version.
+func upperPartial(v SemVer, n int) SemVer {
+	if n > 0 {
+		v = bumpPartial(v, n)
+	}
+	return SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch,
+		Pre: []string{"0"}}
+}
+
+// bumpPartial increments the n-th component of v, resetting the rest.
+func bumpPartial(v SemVer, n int) SemVer {
+	switch n {
+	case 1:
+		return SemVer{Major: v.Major + 1}
+	case 2:
+		return SemVer{Major: v.Major, Minor: v.Minor + 1}
+	}
+	return SemVer{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+func (c comparator) check(v SemVer) bool {
+	r := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return r == 0
+	case "!=":
+		return r != 0
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	case "<":
+		return r < 0
+	}
+	return r <= 0
+}
+
+// reason explains why v doesn't satisfy the comparator.
+func (c comparator) reason(v SemVer) string {
+	var s string
+	switch c.op {
+	case "=":
+		s = "is not equal to"
+	case "!=":
+		s = "is equal to"
+	case ">":
+		s = "is less than or equal to"
+	case ">=":
+		s = "is less than"
+	case "<":
+		s = "is greater than or equal to"
+	default:
+		s = "is greater than"
+	}
+	return fmt.Sprintf("%s %s %s", v, s, c.v)
+}
+
+// Satisfies reports whether v satisfies the constraint.
+func (c Constraint) Satisfies(v Version) bool {
+	return c.SatisfiesSemVer(v.SemVer())
+}
+
+// SatisfiesSemVer reports whether s satisfies the constraint.
+func (c Constraint) SatisfiesSemVer(s SemVer) bool {
+	return c.CheckSemVer(s) == nil
+}
+
+// Check returns error explaining why v doesn't satisfy the constraint,
+// or nil if it does.
+func (c Constraint) Check(v Version) error {
+	return c.CheckSemVer(v.SemVer())
+}
+
+// CheckSemVer returns error explaining why s doesn't satisfy the constraint,
+// or nil if it does.
+func (c Constraint) CheckSemVer(s SemVer) error {
+	reasons := make([]string, 0, len(c.sets))
+	for _, set := range c.sets {
+		ok := true
+		for _, cmp := range set {
+			if !cmp.check(s) {
+				reasons = append(reasons, cmp.reason(s))
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not satisfy %q: %s", s, c.text,
+		strings.Join(reasons, ", and "))
+}
+
+// Max returns the highest version in vs that satisfies the constraint,
+// it returns false if none does.
+func (c Constraint) Max(vs []Version) (max Version, ok bool) {
+	for _, v := range vs {
+		if c.Satisfies(v) && (!ok || max.Before(v)) {
+			max, ok = v, true
+		}
+	}
+	return
+}
+
+// MaxSemVer returns the highest version in ss that satisfies the constraint,
+// it returns false if none does.
+func (c Constraint) MaxSemVer(ss []SemVer) (max SemVer, ok bool) {
+	for _, s := range ss {
+		if c.SatisfiesSemVer(s) && (!ok || max.Less(s)) {
+			max, ok = s, true
+		}
+	}
+	return
+}
+
+// String returns the constraint text.
+func (c Constraint) String() string {
+	return c.text
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (c Constraint) MarshalText() ([]byte, error) {
+	return []byte(c.text), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *Constraint) UnmarshalText(b []byte) (err error) {
+	*c, err = ParseConstraint(string(b))
+	return
+}
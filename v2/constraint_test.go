@@ -0,0 +1,179 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Constraint", func() {
+	parse := func(s string) Constraint {
+		c, err := ParseConstraint(s)
+		Expect(err).To(Succeed(), s)
+		return c
+	}
+
+	semver := func(s string) SemVer {
+		v, err := ParseSemVer(s)
+		Expect(err).To(Succeed(), s)
+		return v
+	}
+
+	DescribeTable("SatisfiesSemVer",
+		func(c string, in, out []string) {
+			cs := parse(c)
+			for _, s := range in {
+				Expect(cs.SatisfiesSemVer(semver(s))).To(BeTrue(), s)
+			}
+			for _, s := range out {
+				Expect(cs.SatisfiesSemVer(semver(s))).To(BeFalse(), s)
+			}
+		},
+		Entry("equal", "=1.2.3",
+			[]string{"1.2.3", "1.2.3+b"}, []string{"1.2.4", "1.2.3-rc"}),
+		Entry("bare", "1.2.3", []string{"1.2.3"}, []string{"1.2.2"}),
+		Entry("not equal", "!=1.2.3",
+			[]string{"1.2.2", "1.2.4"}, []string{"1.2.3"}),
+		Entry("greater", ">1.2.3",
+			[]string{"1.2.4", "2.0.0"}, []string{"1.2.3", "1.0.0"}),
+		Entry("greater partial", ">1.2",
+			[]string{"1.3.0"}, []string{"1.2.9"}),
+		Entry("greater equal", ">= 1.2",
+			[]string{"1.2.0", "3.0.0"}, []string{"1.1.9", "1.2.0-rc.1"}),
+		Entry("less", "<2.0.0",
+			[]string{"1.9.9"}, []string{"2.0.0-rc.1", "2.0.0"}),
+		Entry("less equal partial", "<=1.2",
+			[]string{"1.2.99"}, []string{"1.3.0"}),
+		Entry("range", ">=1.2.0 <2.0.0",
+			[]string{"1.2.0", "1.99.0"}, []string{"1.1.0", "2.0.0"}),
+		Entry("comma", ">=1.2.0, <2.0.0",
+			[]string{"1.2.0"}, []string{"2.0.0"}),
+		Entry("caret", "^1.2.3",
+			[]string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}),
+		Entry("caret minor", "^0.2.3",
+			[]string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}),
+		Entry("caret patch", "^0.0.3",
+			[]string{"0.0.3"}, []string{"0.0.4"}),
+		Entry("caret partial", "^1.2",
+			[]string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}),
+		Entry("caret zero", "^0.0",
+			[]string{"0.0.9"}, []string{"0.1.0"}),
+		Entry("tilde", "~1.2.3",
+			[]string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}),
+		Entry("tilde major", "~1",
+			[]string{"1.0.0", "1.9.9"}, []string{"2.0.0"}),
+		Entry("wildcard", "1.x",
+			[]string{"1.0.0", "1.9.9"}, []string{"0.9.9", "2.0.0"}),
+		Entry("wildcard minor", "1.2.*",
+			[]string{"1.2.0"}, []string{"1.3.0"}),
+		Entry("any", "*", []string{"0.0.0", "99.0.0"}, []string{}),
+		Entry("hyphen", "1.2.3 - 2.3.4",
+			[]string{"1.2.3", "2.3.4"}, []string{"1.2.2", "2.3.5"}),
+		Entry("hyphen partial", "1.2 - 2.3",
+			[]string{"1.2.0", "2.3.9"}, []string{"1.1.9", "2.4.0"}),
+		Entry("union", "^1.2 || >=3.0.0 <3.1",
+			[]string{"1.5.0", "3.0.5"}, []string{"2.0.0", "3.1.0"}),
+		Entry("caret excludes next pre-release", "^1.2",
+			[]string{"1.9.9-rc.1"}, []string{"2.0.0-alpha", "2.0.0-0"}),
+		Entry("tilde excludes next pre-release", "~1.2.3",
+			[]string{"1.2.4-beta"}, []string{"1.3.0-alpha"}),
+		Entry("wildcard excludes next pre-release", "1.x",
+			[]string{"1.0.1-rc.1"}, []string{"2.0.0-alpha", "0.9.9"}),
+		Entry("less equal partial excludes next pre-release", "<=1.2",
+			[]string{"1.2.0"}, []string{"1.3.0-alpha"}),
+		Entry("hyphen excludes next pre-release", "1.2 - 2.3",
+			[]string{"2.3.9"}, []string{"2.4.0-alpha"}),
+		Entry("less excludes pre-release", "<1.2.0",
+			[]string{"1.1.9", "1.1.9-rc.1"}, []string{"1.2.0-rc.1", "1.2.0-0"}),
+		Entry("less partial excludes pre-release", "<1.2",
+			[]string{"1.1.9"}, []string{"1.2.0-rc.1", "1.2.0"}),
+		Entry("less pre-release", "<1.2.0-rc.2",
+			[]string{"1.2.0-rc.1"}, []string{"1.2.0-rc.2", "1.2.0"}),
+		Entry("greater any", ">*",
+			[]string{}, []string{"0.0.0-alpha", "0.0.0", "1.0.0"}),
+		Entry("pre-release", ">=1.0.0-rc.1",
+			[]string{"1.0.0-rc.2", "1.0.0"}, []string{"1.0.0-beta"}),
+	)
+
+	DescribeTable("ParseConstraint invalid",
+		func(c, err string) {
+			_, e := ParseConstraint(c)
+			Expect(e).To(MatchError(err))
+		},
+		Entry("operator", "=>1.2.3", `Invalid constraint operator: "=>1.2.3"`),
+		Entry("version", ">=1.2.a", `Invalid constraint version: "1.2.a"`),
+		Entry("wildcard", "1.x.3", `Invalid constraint version: "1.x.3"`),
+		Entry("partial pre", "1.2-rc", `Invalid constraint version: "1.2-rc"`),
+		Entry("not equal", "!=1.2",
+			`Invalid constraint "!=1.2": != needs full version`),
+		Entry("empty", ">=", `Invalid constraint version: ""`),
+	)
+
+	It("should satisfy Version", func() {
+		c := parse(">=1.2.0 <2.0.0")
+		Expect(c.Satisfies(MakeVersion(1, 2, 0))).To(BeTrue())
+		Expect(c.Satisfies(MakeVersion(2, 0, 0))).To(BeFalse())
+	})
+
+	DescribeTable("Check",
+		func(c string, v Version, err string) {
+			Expect(parse(c).Check(v)).To(MatchError(err))
+		},
+		Entry("lower", ">=1.2.0 <2.0.0", MakeVersion(1, 0, 0),
+			`1.0.0 does not satisfy ">=1.2.0 <2.0.0": `+
+				`1.0.0 is less than 1.2.0`),
+		Entry("upper", "^1.2", MakeVersion(2, 0, 0),
+			`2.0.0 does not satisfy "^1.2": `+
+				`2.0.0 is greater than or equal to 2.0.0-0`),
+		Entry("union", "~1.2.3 || =3.0.0", MakeVersion(1, 3, 0),
+			`1.3.0 does not satisfy "~1.2.3 || =3.0.0": `+
+				`1.3.0 is greater than or equal to 1.3.0-0, `+
+				`and 1.3.0 is not equal to 3.0.0`),
+	)
+
+	It("should not error when satisfied", func() {
+		Expect(parse("^1").Check(MakeVersion(1, 2, 3))).To(Succeed())
+	})
+
+	Describe("Max", func() {
+		vs := []Version{
+			MakeVersion(1, 2, 0), MakeVersion(1, 9, 3),
+			MakeVersion(2, 0, 0), MakeVersion(1, 10, 0),
+		}
+
+		It("should find max satisfying version", func() {
+			v, ok := parse("^1.2").Max(vs)
+			Expect(ok).To(BeTrue())
+			Expect(v).To(Equal(MakeVersion(1, 10, 0)))
+		})
+
+		It("should report none", func() {
+			_, ok := parse(">2.0.0").Max(vs)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should find max satisfying SemVer", func() {
+			s, ok := parse("<2.0.0").MaxSemVer([]SemVer{
+				semver("1.0.0"), semver("1.1.0-rc.1"), semver("2.0.0-rc.1"),
+				semver("2.0.0"),
+			})
+			Expect(ok).To(BeTrue())
+			Expect(s.String()).To(Equal("1.1.0-rc.1"))
+		})
+	})
+
+	It("should be encoded as text", func() {
+		Expect(parse(" >=1.2 <2 ").MarshalText()).
+			To(Equal([]byte(">=1.2 <2")))
+
+		b, err := json.Marshal(parse(">=1.2 <2"))
+		Expect(err).To(Succeed())
+
+		var c Constraint
+		Expect(json.Unmarshal(b, &c)).To(Succeed())
+		Expect(c.Satisfies(MakeVersion(1, 5, 0))).To(BeTrue())
+	})
+})
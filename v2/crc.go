@@ -0,0 +1,378 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// CRCParams is the CRC model of the CRC catalogue
+// (https://reveng.sourceforge.io/crc-catalogue/).
+type CRCParams struct {
+	Name string
+	// Width in bits, from 8 to 64
+	Width  int
+	Poly   uint64
+	Init   uint64
+	RefIn  bool
+	RefOut bool
+	XorOut uint64
+	// Checksum of ``123456789'', zero skips the check in MakeCRC
+	Check uint64
+	// Byte order of the checksum appended to the frame, nil is
+	// binary.BigEndian. This is not part of the catalogue model.
+	ByteOrder binary.ByteOrder
+}
+
+// CRC is table driven CRC calculator, it is safe for concurrent use.
+// Use MakeCRC or the catalogue presets like CRC16_MODBUS.
+type CRC struct {
+	CRCParams
+	table *[256]uint64
+}
+
+// CRC catalogue presets, the checksums are appended in big endian except
+// CRC-16/MODBUS, CRC-16/KERMIT, CRC-16/IBM-SDLC and CRC-16/DNP which are
+// sent least significant byte first.
+var (
+	CRC8_SMBUS = mustCRC(CRCParams{Name: "CRC-8/SMBUS", Width: 8,
+		Poly: 0x07, Check: 0xF4})
+	CRC8_MAXIM_DOW = mustCRC(CRCParams{Name: "CRC-8/MAXIM-DOW", Width: 8,
+		Poly: 0x31, RefIn: true, RefOut: true, Check: 0xA1})
+	CRC8_CDMA2000 = mustCRC(CRCParams{Name: "CRC-8/CDMA2000", Width: 8,
+		Poly: 0x9B, Init: 0xFF, Check: 0xDA})
+
+	CRC16_ARC = mustCRC(CRCParams{Name: "CRC-16/ARC", Width: 16,
+		Poly: 0x8005, RefIn: true, RefOut: true, Check: 0xBB3D})
+	CRC16_MODBUS = mustCRC(CRCParams{Name: "CRC-16/MODBUS", Width: 16,
+		Poly: 0x8005, Init: 0xFFFF, RefIn: true, RefOut: true, Check: 0x4B37,
+		ByteOrder: binary.LittleEndian})
+	// Also known as CRC-16/CCITT-FALSE
+	CRC16_IBM_3740 = mustCRC(CRCParams{Name: "CRC-16/IBM-3740", Width: 16,
+		Poly: 0x1021, Init: 0xFFFF, Check: 0x29B1})
+	CRC16_XMODEM = mustCRC(CRCParams{Name: "CRC-16/XMODEM", Width: 16,
+		Poly: 0x1021, Check: 0x31C3})
+	// Also known as CRC-16/CCITT
+	CRC16_KERMIT = mustCRC(CRCParams{Name: "CRC-16/KERMIT", Width: 16,
+		Poly: 0x1021, RefIn: true, RefOut: true, Check: 0x2189,
+		ByteOrder: binary.LittleEndian})
+	// Also known as CRC-16/X-25
+	CRC16_IBM_SDLC = mustCRC(CRCParams{Name: "CRC-16/IBM-SDLC", Width: 16,
+		Poly: 0x1021, Init: 0xFFFF, RefIn: true, RefOut: true,
+		XorOut: 0xFFFF, Check: 0x906E, ByteOrder: binary.LittleEndian})
+	CRC16_DNP = mustCRC(CRCParams{Name: "CRC-16/DNP", Width: 16,
+		Poly: 0x3D65, RefIn: true, RefOut: true, XorOut: 0xFFFF,
+		Check: 0xEA82, ByteOrder: binary.LittleEndian})
+
+	// Also known as CRC-32, as used by zip and ethernet
+	CRC32_ISO_HDLC = mustCRC(CRCParams{Name: "CRC-32/ISO-HDLC", Width: 32,
+		Poly: 0x04C11DB7, Init: 0xFFFFFFFF, RefIn: true, RefOut: true,
+		XorOut: 0xFFFFFFFF, Check: 0xCBF43926})
+	// Also known as CRC-32C
+	CRC32_ISCSI = mustCRC(CRCParams{Name: "CRC-32/ISCSI", Width: 32,
+		Poly: 0x1EDC6F41, Init: 0xFFFFFFFF, RefIn: true, RefOut: true,
+		XorOut: 0xFFFFFFFF, Check: 0xE3069283})
+	CRC32_BZIP2 = mustCRC(CRCParams{Name: "CRC-32/BZIP2", Width: 32,
+		Poly: 0x04C11DB7, Init: 0xFFFFFFFF, XorOut: 0xFFFFFFFF,
+		Check: 0xFC891918})
+	CRC32_MPEG2 = mustCRC(CRCParams{Name: "CRC-32/MPEG-2", Width: 32,
+		Poly: 0x04C11DB7, Init: 0xFFFFFFFF, Check: 0x0376E6E7})
+)
+
+var crcCatalogue = map[string]CRC{}
+
+var crcAliases = map[string]string{
+	"CRC-16/CCITT-FALSE": "CRC-16/IBM-3740",
+	"CRC-16/CCITT":       "CRC-16/KERMIT",
+	"CRC-16/X-25":        "CRC-16/IBM-SDLC",
+	"CRC-32":             "CRC-32/ISO-HDLC",
+	"CRC-32C":            "CRC-32/ISCSI",
+}
+
+func init() {
+	for _, c := range []CRC{CRC8_SMBUS, CRC8_MAXIM_DOW, CRC8_CDMA2000,
+		CRC16_ARC, CRC16_MODBUS, CRC16_IBM_3740, CRC16_XMODEM, CRC16_KERMIT,
+		CRC16_IBM_SDLC, CRC16_DNP, CRC32_ISO_HDLC, CRC32_ISCSI, CRC32_BZIP2,
+		CRC32_MPEG2} {
+		crcCatalogue[c.Name] = c
+	}
+}
+
+// LookupCRC returns the catalogue preset by its case insensitive name or
+// alias, like ``CRC-16/MODBUS'' or ``CRC-16/CCITT-FALSE''.
+func LookupCRC(name string) (CRC, bool) {
+	name = strings.ToUpper(name)
+	if a, ok := crcAliases[name]; ok {
+		name = a
+	}
+	c, ok := crcCatalogue[name]
+	return c, ok
+}
+
+func mustCRC(p CRCParams) CRC {
+	c, err := MakeCRC(p)
+	if err != nil {
+		panic(err.Error())
+	}
+	return c
+}
+
+// reflectBits returns the w low bits of v in reverse order.
+func reflectBits(v uint64, w int) uint64 {
+	var r uint64
+	for i := 0; i < w; i++ {
+		r = r<<1 | v>>i&1
+	}
+	return r
+}
+
+// MakeCRC returns CRC of p model with its lookup table. It returns error if
+// the width is invalid, the parameters don't fit the width, or Check is
+// given and doesn't match.
+func MakeCRC(p CRCParams) (CRC, error) {
+	if p.Width < 8 || p.Width > 64 {
+		return CRC{}, fmt.Errorf("invalid CRC width: %d", p.Width)
+	}
+	mask := p.mask()
+	if p.Poly&^mask != 0 || p.Init&^mask != 0 || p.XorOut&^mask != 0 ||
+		p.Check&^mask != 0 {
+		return CRC{}, fmt.Errorf("CRC parameter overflows %d bits", p.Width)
+	}
+
+	c := CRC{CRCParams: p, table: &[256]uint64{}}
+	if p.RefIn {
+		poly := reflectBits(p.Poly, p.Width)
+		for i := range c.table {
+			v := uint64(i)
+			for k := 0; k < 8; k++ {
+				if v&1 != 0 {
+					v = v>>1 ^ poly
+				} else {
+					v >>= 1
+				}
+			}
+			c.table[i] = v
+		}
+	} else {
+		top := uint64(1) << (p.Width - 1)
+		for i := range c.table {
+			v := uint64(i) << (p.Width - 8)
+			for k := 0; k < 8; k++ {
+				if v&top != 0 {
+					v = v<<1 ^ p.Poly
+				} else {
+					v <<= 1
+				}
+			}
+			c.table[i] = v & mask
+		}
+	}
+
+	if p.Check != 0 {
+		if v := c.Checksum([]byte("123456789")); v != p.Check {
+			return CRC{}, fmt.Errorf("CRC check mismatch: %0*x, expecting %0*x",
+				c.Size()*2, v, c.Size()*2, p.Check)
+		}
+	}
+	return c, nil
+}
+
+func (p CRCParams) mask() uint64 {
+	return ^uint64(0) >> (64 - p.Width)
+}
+
+// Size returns the checksum size in bytes.
+func (p CRCParams) Size() int {
+	return (p.Width + 7) / 8
+}
+
+// init returns the initial register value.
+func (c CRC) init() uint64 {
+	if c.RefIn {
+		return reflectBits(c.Init, c.Width)
+	}
+	return c.Init
+}
+
+// update returns the register after processing b.
+func (c CRC) update(reg uint64, b []byte) uint64 {
+	if c.RefIn {
+		for _, x := range b {
+			reg = c.table[byte(reg)^x] ^ reg>>8
+		}
+		return reg
+	}
+	mask := c.mask()
+	for _, x := range b {
+		reg = (c.table[byte(reg>>(c.Width-8))^x] ^ reg<<8) & mask
+	}
+	return reg
+}
+
+// final returns the checksum of reg register.
+func (c CRC) final(reg uint64) uint64 {
+	if c.RefIn != c.RefOut {
+		reg = reflectBits(reg, c.Width)
+	}
+	return reg ^ c.XorOut
+}
+
+// Checksum returns the CRC of b.
+func (c CRC) Checksum(b []byte) uint64 {
+	return c.final(c.update(c.init(), b))
+}
+
+// bytes appends v checksum to b in the byte order.
+func (c CRC) bytes(b []byte, v uint64) []byte {
+	return putUint(b, v, c.Size(), c.ByteOrder == binary.LittleEndian)
+}
+
+// Append returns b with its CRC appended in ByteOrder.
+func (c CRC) Append(b []byte) []byte {
+	return c.bytes(b, c.Checksum(b))
+}
+
+// Verify reports whether frame ends with the CRC of the preceding bytes.
+func (c CRC) Verify(frame []byte) bool {
+	n := len(frame) - c.Size()
+	if n < 0 {
+		return false
+	}
+	return string(c.bytes(nil, c.Checksum(frame[:n]))) == string(frame[n:])
+}
+
+// New returns streaming hash.Hash64, its Sum appends the checksum in
+// ByteOrder.
+func (c CRC) New() hash.Hash64 {
+	return &crcHash{c: c, reg: c.init()}
+}
+
+type crcHash struct {
+	c   CRC
+	reg uint64
+}
+
+func (h *crcHash) Write(p []byte) (int, error) {
+	h.reg = h.c.update(h.reg, p)
+	return len(p), nil
+}
+
+func (h *crcHash) Sum(b []byte) []byte {
+	return h.c.bytes(b, h.Sum64())
+}
+
+func (h *crcHash) Sum64() uint64 {
+	return h.c.final(h.reg)
+}
+
+func (h *crcHash) Reset() {
+	h.reg = h.c.init()
+}
+
+func (h *crcHash) Size() int {
+	return h.c.Size()
+}
+
+func (h *crcHash) BlockSize() int {
+	return 1
+}
+
+//============================================================================
+
+// Checksum8 is a single byte checksum, like LRC, XOR, SUM8 and BCC. The zero
+// value is XOR checksum.
+type Checksum8 struct {
+	Name string
+	Init byte
+	// Update returns the accumulator after adding c byte, nil is XOR
+	Update func(acc, c byte) byte
+	// Final returns the checksum of the accumulator, nil is identity
+	Final func(acc byte) byte
+}
+
+// Single byte checksum presets.
+var (
+	// Longitudinal redundancy check as used by Modbus ASCII, two's complement
+	// of the sum of all bytes.
+	LRC = Checksum8{Name: "LRC", Update: addByte, Final: negByte}
+	// XOR of all bytes
+	XOR8 = Checksum8{Name: "XOR", Update: xorByte}
+	// Sum of all bytes modulo 256
+	SUM8 = Checksum8{Name: "SUM8", Update: addByte}
+	// Block check character of STX/ETX protocols, XOR of the bytes after STX
+	// up to and including ETX. Pass the frame without STX.
+	BCC = Checksum8{Name: "BCC", Update: xorByte}
+)
+
+func addByte(acc, c byte) byte { return acc + c }
+func xorByte(acc, c byte) byte { return acc ^ c }
+func negByte(acc byte) byte    { return -acc }
+
+func (s Checksum8) update(acc, c byte) byte {
+	if s.Update == nil {
+		return acc ^ c
+	}
+	return s.Update(acc, c)
+}
+
+func (s Checksum8) final(acc byte) byte {
+	if s.Final == nil {
+		return acc
+	}
+	return s.Final(acc)
+}
+
+// Checksum returns the checksum of b.
+func (s Checksum8) Checksum(b []byte) byte {
+	acc := s.Init
+	for _, c := range b {
+		acc = s.update(acc, c)
+	}
+	return s.final(acc)
+}
+
+// Append returns b with its checksum appended.
+func (s Checksum8) Append(b []byte) []byte {
+	return append(b, s.Checksum(b))
+}
+
+// Verify reports whether frame ends with the checksum of the preceding
+// bytes.
+func (s Checksum8) Verify(frame []byte) bool {
+	n := len(frame) - 1
+	return n >= 0 && s.Checksum(frame[:n]) == frame[n]
+}
+
+// New returns streaming hash.Hash, its Sum appends the checksum byte.
+func (s Checksum8) New() hash.Hash {
+	return &checksum8Hash{s: s, acc: s.Init}
+}
+
+type checksum8Hash struct {
+	s   Checksum8
+	acc byte
+}
+
+func (h *checksum8Hash) Write(p []byte) (int, error) {
+	for _, c := range p {
+		h.acc = h.s.update(h.acc, c)
+	}
+	return len(p), nil
+}
+
+func (h *checksum8Hash) Sum(b []byte) []byte {
+	return append(b, h.s.final(h.acc))
+}
+
+func (h *checksum8Hash) Reset() {
+	h.acc = h.s.Init
+}
+
+func (h *checksum8Hash) Size() int {
+	return 1
+}
+
+func (h *checksum8Hash) BlockSize() int {
+	return 1
+}
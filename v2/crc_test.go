@@ -0,0 +1,133 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"hash/crc32"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CRC", func() {
+	check := []byte("123456789")
+
+	DescribeTable("catalogue check", func(name string, sum uint64) {
+		c, ok := LookupCRC(name)
+		Expect(ok).To(BeTrue())
+		Expect(c.Checksum(check)).To(Equal(sum))
+
+		h := c.New()
+		h.Write(check[:4])
+		h.Write(check[4:])
+		Expect(h.Sum64()).To(Equal(sum))
+		Expect(h.Size()).To(Equal(c.Size()))
+		h.Reset()
+		h.Write(check)
+		Expect(h.Sum64()).To(Equal(sum))
+	},
+		Entry("CRC-8/SMBUS", "CRC-8/SMBUS", uint64(0xF4)),
+		Entry("CRC-8/MAXIM-DOW", "crc-8/maxim-dow", uint64(0xA1)),
+		Entry("CRC-8/CDMA2000", "CRC-8/CDMA2000", uint64(0xDA)),
+		Entry("CRC-16/ARC", "CRC-16/ARC", uint64(0xBB3D)),
+		Entry("CRC-16/MODBUS", "CRC-16/MODBUS", uint64(0x4B37)),
+		Entry("CRC-16/CCITT-FALSE", "CRC-16/CCITT-FALSE", uint64(0x29B1)),
+		Entry("CRC-16/XMODEM", "CRC-16/XMODEM", uint64(0x31C3)),
+		Entry("CRC-16/CCITT", "CRC-16/CCITT", uint64(0x2189)),
+		Entry("CRC-16/X-25", "CRC-16/X-25", uint64(0x906E)),
+		Entry("CRC-16/DNP", "CRC-16/DNP", uint64(0xEA82)),
+		Entry("CRC-32", "CRC-32", uint64(0xCBF43926)),
+		Entry("CRC-32C", "CRC-32C", uint64(0xE3069283)),
+		Entry("CRC-32/BZIP2", "CRC-32/BZIP2", uint64(0xFC891918)),
+		Entry("CRC-32/MPEG-2", "CRC-32/MPEG-2", uint64(0x0376E6E7)),
+	)
+
+	It("should not find unknown preset", func() {
+		_, ok := LookupCRC("CRC-16/NONE")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should match hash/crc32", func() {
+		b := []byte("The quick brown fox jumps over the lazy dog")
+		Expect(CRC32_ISO_HDLC.Checksum(b)).To(
+			Equal(uint64(crc32.ChecksumIEEE(b))))
+		Expect(CRC32_ISCSI.Checksum(b)).To(Equal(uint64(
+			crc32.Checksum(b, crc32.MakeTable(crc32.Castagnoli)))))
+		Expect(CRC32_ISO_HDLC.New().Sum([]byte{0xFF})).To(
+			Equal(crc32.NewIEEE().Sum([]byte{0xFF})))
+	})
+
+	It("should append and verify modbus frame", func() {
+		frame := CRC16_MODBUS.Append([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A})
+		Expect(frame).To(EqualBytes([]byte{
+			0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}))
+		Expect(CRC16_MODBUS.Verify(frame)).To(BeTrue())
+		frame[2] = 0x01
+		Expect(CRC16_MODBUS.Verify(frame)).To(BeFalse())
+		Expect(CRC16_MODBUS.Verify([]byte{0x01})).To(BeFalse())
+
+		h := CRC16_MODBUS.New()
+		h.Write([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A})
+		Expect(h.Sum(nil)).To(EqualBytes([]byte{0xC5, 0xCD}))
+	})
+
+	It("should append big endian", func() {
+		Expect(CRC16_XMODEM.Append(check)[9:]).To(
+			EqualBytes([]byte{0x31, 0xC3}))
+	})
+
+	It("should make custom CRC", func() {
+		c, err := MakeCRC(CRCParams{Name: "CRC-24/OPENPGP", Width: 24,
+			Poly: 0x864CFB, Init: 0xB704CE, Check: 0x21CF02})
+		Expect(err).To(Succeed())
+		Expect(c.Size()).To(Equal(3))
+
+		c, err = MakeCRC(CRCParams{Name: "CRC-64/XZ", Width: 64,
+			Poly: 0x42F0E1EBA9EA3693, Init: 0xFFFFFFFFFFFFFFFF, RefIn: true,
+			RefOut: true, XorOut: 0xFFFFFFFFFFFFFFFF,
+			Check: 0x995DC9BBDF1939FA})
+		Expect(err).To(Succeed())
+		Expect(c.Size()).To(Equal(8))
+	})
+
+	DescribeTable("invalid params", func(p CRCParams, msg string) {
+		_, err := MakeCRC(p)
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("width", CRCParams{Width: 5}, "invalid CRC width: 5"),
+		Entry("overflow", CRCParams{Width: 8, Poly: 0x107},
+			"CRC parameter overflows 8 bits"),
+		Entry("check", CRCParams{Width: 16, Poly: 0x8005, Check: 0x1234},
+			"CRC check mismatch: fee8, expecting 1234"),
+	)
+})
+
+var _ = Describe("Checksum8", func() {
+	DescribeTable("checksum", func(s Checksum8, b []byte, sum byte) {
+		Expect(s.Checksum(b)).To(Equal(sum))
+		frame := s.Append(b)
+		Expect(frame).To(HaveLen(len(b) + 1))
+		Expect(s.Verify(frame)).To(BeTrue())
+		frame[len(frame)-1]++
+		Expect(s.Verify(frame)).To(BeFalse())
+
+		h := s.New()
+		h.Write(b)
+		Expect(h.Sum(nil)).To(Equal([]byte{sum}))
+	},
+		Entry("LRC", LRC, []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x01},
+			byte(0xFB)),
+		Entry("XOR", XOR8, []byte{0x01, 0x02, 0x04, 0x0F}, byte(0x08)),
+		Entry("SUM8", SUM8, []byte{0x80, 0x90, 0x01}, byte(0x11)),
+		Entry("BCC", BCC, []byte{'A', 'B', 0x03}, byte(0x00)),
+		Entry("empty", LRC, []byte{}, byte(0x00)),
+		Entry("zero value", Checksum8{}, []byte{0x01, 0x02, 0x04, 0x0F},
+			byte(0x08)),
+		Entry("zero value init", Checksum8{Init: 0xFF}, []byte{0x0F},
+			byte(0xF0)),
+	)
+
+	It("should not verify empty frame", func() {
+		Expect(XOR8.Verify(nil)).To(BeFalse())
+	})
+})
@@ -1,15 +1,25 @@
 package util
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/fxamacker/cbor/v2"
 )
 
 const (
 	date_format = "2006-01-02 -07:00"
 )
 
+// CBOR_TAG_DATE is the CBOR tag number for Date binary format. It is an
+// unregistered private tag, not assigned by IANA, so other CBOR decoders
+// may not recognize it or may use the number for something else.
+const CBOR_TAG_DATE uint64 = 51268
+
 // Date represents a date with timezone.
 //
 // This date can be efficiently encoded to 4 bytes using binary format similar
@@ -33,6 +43,11 @@ func (d Date) Equal(o Date) bool {
 	return d.Time().Equal(o.Time())
 }
 
+// TZ returns the time zone offset of date.
+func (d Date) TZ() TZ {
+	return TimeToTZ(d.tm)
+}
+
 // Time returns the starting time of date.
 func (d Date) Time() time.Time {
 	return d.tm
@@ -57,6 +72,73 @@ func (d *Date) UnmarshalText(b []byte) (err error) {
 	return
 }
 
+type dateObject struct {
+	Date string `json:"date"`
+	TZ   string `json:"tz"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The date is encoded as JSON_STRING, use DateJson for other forms.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// All JSON forms are accepted, the epoch form will be decoded as UTC date
+// because it doesn't have time zone information.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	switch c := jsonKind(b); {
+	case c == 'n':
+		if bytes.Equal(bytes.TrimSpace(b), jsonNull) {
+			return nil
+		}
+	case c == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	case c == '{':
+		var o dateObject
+		if err := json.Unmarshal(b, &o); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(o.Date + " " + o.TZ))
+	case c == '-' || (c >= '0' && c <= '9'):
+		var n int64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return err
+		}
+		*d = MakeDate(time.Unix(n, 0).UTC())
+		return nil
+	}
+	return fmt.Errorf("invalid json for %T: %s", d, b)
+}
+
+// DateJson wraps Date to be encoded in Form JSON form, e.g.
+//     json.Marshal(DateJson{Date: d, Form: JSON_EPOCH})
+// All JSON forms are decoded like Date.
+type DateJson struct {
+	Date
+	Form JsonForm
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (j DateJson) MarshalJSON() ([]byte, error) {
+	switch j.Form {
+	case JSON_OBJECT:
+		return json.Marshal(dateObject{
+			Date: j.tm.Format("2006-01-02"),
+			TZ:   j.tm.Format("-07:00"),
+		})
+	case JSON_EPOCH:
+		y, m, day := j.tm.Date()
+		n := time.Date(y, m, day, 0, 0, 0, 0, time.UTC).Unix()
+		return []byte(strconv.FormatInt(n, 10)), nil
+	}
+	return j.Date.MarshalJSON()
+}
+
 // MarshalBinary implements the encoding.BinaryMarshaler interface.
 // See the documentation on the Date type for more details.
 func (d Date) MarshalBinary() (b []byte, err error) {
@@ -66,13 +148,16 @@ func (d Date) MarshalBinary() (b []byte, err error) {
 		return
 	}
 
-	_, z := d.tm.Zone()
+	z, err := d.TZ().bits()
+	if err != nil {
+		return
+	}
 
 	var n uint32 = 0xB << 28
 	n |= uint32(y) << 16
 	n |= (uint32(m) - 1) << 12
 	n |= uint32(day-1) << 7
-	n |= uint32(z/900 + 64)
+	n |= uint32(z)
 
 	b = make([]byte, 4)
 	binary.BigEndian.PutUint32(b, n)
@@ -103,20 +188,12 @@ func (d *Date) UnmarshalBinary(b []byte) error {
 		return fmt.Errorf("invalid day: %d", day)
 	}
 
-	z := int(b[3] & 0x7F)
-	rz := (z - 64) * 15
-	if z > 125 {
-		return fmt.Errorf("invalid timezone: %02d:%02d", rz/60, rz%60)
+	tz, err := tzFromBits(b[3])
+	if err != nil {
+		return err
 	}
 
-	var loc *time.Location
-	if _, local := time.Now().Zone(); rz*60 == local {
-		loc = time.Local
-	} else {
-		loc = time.FixedZone("", rz*60)
-	}
-
-	tm := time.Date(y, time.Month(m), day, 0, 0, 0, 0, loc)
+	tm := time.Date(y, time.Month(m), day, 0, 0, 0, 0, tz.Location())
 	ay, am, ad := tm.Date()
 	if ay != y || am != time.Month(m) || ad != day {
 		return fmt.Errorf("invalid date: %04d-%02d-%02d", y, m, day)
@@ -125,3 +202,37 @@ func (d *Date) UnmarshalBinary(b []byte) error {
 	d.tm = tm
 	return nil
 }
+
+// MarshalCBOR implements the cbor.Marshaler interface.
+// The date is encoded as the binary format wrapped in CBOR_TAG_DATE tag.
+func (d Date) MarshalCBOR() ([]byte, error) {
+	b, err := d.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Marshal(cbor.Tag{Number: CBOR_TAG_DATE, Content: b})
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface.
+// Beside the MarshalCBOR output, untagged binary format byte string and
+// text format string are also accepted.
+func (d *Date) UnmarshalCBOR(b []byte) error {
+	var v interface{}
+	if err := cbor.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if t, ok := v.(cbor.Tag); ok {
+		if t.Number != CBOR_TAG_DATE {
+			return fmt.Errorf("invalid cbor tag: %d", t.Number)
+		}
+		v = t.Content
+	}
+
+	switch c := v.(type) {
+	case []byte:
+		return d.UnmarshalBinary(c)
+	case string:
+		return d.UnmarshalText([]byte(c))
+	}
+	return fmt.Errorf("invalid cbor type for %T: %T", d, v)
+}
@@ -5,6 +5,8 @@ import (
 	. "github.com/hanindo/util/v2"
 	"time"
 
+	"github.com/fxamacker/cbor/v2"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -19,6 +21,7 @@ var _ = Describe("Date", func() {
 			x, _ := time.Parse(time.RFC3339, sx)
 			d = MakeDate(t)
 			Expect(d.Time()).To(Equal(x), "Time")
+			Expect(d.TZ().String()).To(Equal(str[11:]), "TZ")
 			Expect(d.String()).To(Equal(str), "String")
 
 			b, err := json.Marshal(d)
@@ -89,6 +92,83 @@ var _ = Describe("Date", func() {
 		})
 	})
 
+	Describe("JSON", func() {
+		t := time.Date(2021, time.February, 1, 23, 24, 25, 0,
+			time.FixedZone("", 7*3600))
+		It("should be encoded as string", func() {
+			Expect(json.Marshal(MakeDate(t))).
+				To(Equal([]byte(`"2021-02-01 +07:00"`)))
+		})
+
+		DescribeTable("forms",
+			func(f JsonForm, x, xd string) {
+				b, err := json.Marshal(DateJson{Date: MakeDate(t), Form: f})
+				Expect(err).To(Succeed(), "encode err")
+				Expect(string(b)).To(Equal(x), "encode")
+
+				var nd Date
+				Expect(json.Unmarshal(b, &nd)).To(Succeed(), "decode err")
+				Expect(nd.String()).To(Equal(xd), "decode")
+			},
+			Entry("string", JSON_STRING, `"2021-02-01 +07:00"`,
+				"2021-02-01 +07:00"),
+			Entry("object", JSON_OBJECT,
+				`{"date":"2021-02-01","tz":"+07:00"}`,
+				"2021-02-01 +07:00"),
+			Entry("epoch", JSON_EPOCH, `1612137600`, "2021-02-01 +00:00"),
+		)
+
+		It("should ignore null", func() {
+			nd := MakeDate(t)
+			Expect(json.Unmarshal([]byte(`null`), &nd)).To(Succeed())
+			Expect(nd.Equal(MakeDate(t))).To(BeTrue())
+		})
+
+		It("should reject other value", func() {
+			var nd Date
+			Expect(json.Unmarshal([]byte(`true`), &nd)).
+				To(MatchError("invalid json for *util.Date: true"))
+		})
+	})
+
+	Describe("CBOR", func() {
+		t := time.Date(2021, time.February, 1, 23, 24, 25, 0,
+			time.FixedZone("", 20700))
+
+		It("should be encoded as tagged binary", func() {
+			b, err := cbor.Marshal(MakeDate(t))
+			Expect(err).To(Succeed(), "encode err")
+			Expect(FancyHex(b)).To(Equal("d9 c8 44 44 b7 e5 1. 57"), "encode")
+
+			var nd Date
+			Expect(cbor.Unmarshal(b, &nd)).To(Succeed(), "decode err")
+			Expect(nd.String()).To(Equal("2021-02-01 +05:45"), "decode")
+		})
+
+		DescribeTable("decode",
+			func(b []byte) {
+				var nd Date
+				Expect(cbor.Unmarshal(b, &nd)).To(Succeed())
+				Expect(nd.String()).To(Equal("2021-02-01 +05:45"))
+			},
+			Entry("binary", []byte{0x44, 0xB7, 0xE5, 0x10, 0x57}),
+			Entry("text", append([]byte{0x71}, "2021-02-01 +05:45"...)),
+		)
+
+		DescribeTable("invalid",
+			func(err string, b []byte) {
+				var nd Date
+				Expect(cbor.Unmarshal(b, &nd)).To(MatchError(err))
+			},
+			Entry("tag", "invalid cbor tag: 100",
+				[]byte{0xD8, 0x64, 0x44, 0xB7, 0xE5, 0x10, 0x57}),
+			Entry("type", "invalid cbor type for *util.Date: uint64",
+				[]byte{0x01}),
+			Entry("binary", "invalid length: 3",
+				[]byte{0x43, 0xB7, 0xE5, 0x10}),
+		)
+	})
+
 	Describe("MarshalBinary", func() {
 		Context("minus year", func() {
 			It("should error", func() {
@@ -99,6 +179,16 @@ var _ = Describe("Date", func() {
 			})
 		})
 
+		Context("non quarter hour timezone", func() {
+			It("should error", func() {
+				t := time.Date(2021, time.January, 1, 0, 0, 0, 0,
+					time.FixedZone("", 353*60))
+				b, err := MakeDate(t).MarshalBinary()
+				Expect(b).To(BeEmpty())
+				Expect(err).To(MatchError("invalid timezone: +05:53"))
+			})
+		})
+
 		Context("year > 4094", func() {
 			It("should error", func() {
 				t := time.Date(4095, time.January, 1, 0, 0, 0, 0, time.UTC)
@@ -0,0 +1,357 @@
+package util
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode is the rounding of decimal places that don't fit.
+type RoundingMode int
+
+const (
+	// Half away from zero, like Round, 1.25 is 1.3 and -1.25 is -1.3
+	ROUND_HALF_UP RoundingMode = iota
+	// Half to even or banker's rounding, 1.25 is 1.2 and 1.35 is 1.4
+	ROUND_HALF_EVEN
+	// Toward zero or truncation, 1.29 is 1.2 and -1.29 is -1.2
+	ROUND_DOWN
+	// Away from zero, 1.21 is 1.3 and -1.21 is -1.3
+	ROUND_UP
+	// Toward positive infinity, 1.21 is 1.3 and -1.29 is -1.2
+	ROUND_CEILING
+	// Toward negative infinity, 1.29 is 1.2 and -1.21 is -1.3
+	ROUND_FLOOR
+)
+
+// Maximum absolute scale of ParseDecimal, larger exponent like ``1e-99999''
+// is rejected as it would take too much time and memory.
+const DECIMAL_MAX_SCALE = 4096
+
+// Decimal is exact fixed-point decimal number of arbitrary precision, the
+// value is an unscaled integer times 10 to the power of minus scale, e.g.
+// 12345 of scale 2 is 123.45. The scale is kept by the operations, so
+// ``1.50'' stays ``1.50''. The zero value is 0 of scale 0.
+//
+// Decimal is immutable and safe for concurrent use. Use Cmp or Equal
+// instead of ``=='' to compare values.
+type Decimal struct {
+	n     *big.Int
+	scale int
+}
+
+var bigTen = big.NewInt(10)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// MakeDecimal composes a decimal of unscaled value and scale, e.g.
+// MakeDecimal(12345, 2) is 123.45. Negative scale multiplies the value.
+func MakeDecimal(unscaled int64, scale int) Decimal {
+	return makeDecimal(big.NewInt(unscaled), scale)
+}
+
+func makeDecimal(n *big.Int, scale int) Decimal {
+	if scale < 0 {
+		return Decimal{n: n.Mul(n, pow10(-scale))}
+	}
+	return Decimal{n: n, scale: scale}
+}
+
+var decimalExpRE = regexp.MustCompile(
+	`^([-+]?)(\d*)(?:\.(\d*))?(?:[eE]([-+]?\d{1,9}))?$`)
+
+// ParseDecimal parses s decimal like ``-123.450'', keeping its scale. The
+// exponent form like ``1.5e-3'' is also accepted, as long as the absolute
+// scale doesn't exceed DECIMAL_MAX_SCALE.
+func ParseDecimal(s string) (Decimal, error) {
+	m := decimalExpRE.FindStringSubmatch(s)
+	if m == nil || m[2] == "" && m[3] == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	scale := len(m[3])
+	if m[4] != "" {
+		exp, _ := strconv.Atoi(m[4])
+		scale -= exp
+	}
+	if scale > DECIMAL_MAX_SCALE || scale < -DECIMAL_MAX_SCALE {
+		return Decimal{}, fmt.Errorf("decimal scale out of range: %q", s)
+	}
+	n, _ := new(big.Int).SetString(m[1]+m[2]+m[3], 10)
+	return makeDecimal(n, scale), nil
+}
+
+// FloatToDecimal returns the decimal of x shortest representation, e.g.
+// 1.005 is 1.005 instead of its binary value 1.00499999999999989...
+func FloatToDecimal(x float64) (Decimal, error) {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return Decimal{}, fmt.Errorf("invalid decimal: %v", x)
+	}
+	return ParseDecimal(strconv.FormatFloat(x, 'f', -1, 64))
+}
+
+// unscaled returns the unscaled value, it must not be modified.
+func (d Decimal) unscaled() *big.Int {
+	if d.n == nil {
+		return new(big.Int)
+	}
+	return d.n
+}
+
+// Unscaled returns a copy of the unscaled value.
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.unscaled())
+}
+
+// Scale returns the number of decimal places.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 by the sign of d.
+func (d Decimal) Sign() int {
+	return d.unscaled().Sign()
+}
+
+// IsZero reports whether d is zero of any scale.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// rescale returns the unscaled value of d in scale, which must not be less
+// than d scale.
+func (d Decimal) rescale(scale int) *big.Int {
+	n := d.Unscaled()
+	if scale > d.scale {
+		n.Mul(n, pow10(scale-d.scale))
+	}
+	return n
+}
+
+// align returns the unscaled values of d and o in their larger scale.
+func (d Decimal) align(o Decimal) (*big.Int, *big.Int, int) {
+	scale := d.scale
+	if o.scale > scale {
+		scale = o.scale
+	}
+	return d.rescale(scale), o.rescale(scale), scale
+}
+
+// Cmp compares d and o regardless of their scales, it returns -1 if d is
+// less than o, 0 if they are equal and +1 if d is greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b, _ := d.align(o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o have the same value, 1.5 equals 1.50.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{n: d.Unscaled().Neg(d.unscaled()), scale: d.scale}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return Decimal{n: d.Unscaled().Abs(d.unscaled()), scale: d.scale}
+}
+
+// Add returns d + o in the larger scale.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b, scale := d.align(o)
+	return Decimal{n: a.Add(a, b), scale: scale}
+}
+
+// Sub returns d - o in the larger scale.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b, scale := d.align(o)
+	return Decimal{n: a.Sub(a, b), scale: scale}
+}
+
+// Mul returns d * o in the sum of their scales.
+func (d Decimal) Mul(o Decimal) Decimal {
+	n := d.Unscaled()
+	return Decimal{n: n.Mul(n, o.unscaled()), scale: d.scale + o.scale}
+}
+
+// Quo returns d / o rounded to scale decimal places by mode, negative scale
+// rounds to tens, hundreds and so on like Round. It returns error if o is
+// zero.
+func (d Decimal) Quo(o Decimal, scale int,
+	mode RoundingMode) (Decimal, error) {
+	if o.IsZero() {
+		return Decimal{}, fmt.Errorf("decimal division by zero")
+	}
+	// d / o = (n * 10^(scale + o.scale - d.scale) / m) * 10^-scale
+	n, m := d.Unscaled(), o.Unscaled()
+	if e := scale + o.scale - d.scale; e >= 0 {
+		n.Mul(n, pow10(e))
+	} else {
+		m.Mul(m, pow10(-e))
+	}
+	return makeDecimal(quoRound(n, m, mode), scale), nil
+}
+
+// quoRound returns n / m rounded by mode.
+func quoRound(n, m *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, m, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	neg := n.Sign() != m.Sign()
+	var away bool
+	switch mode {
+	case ROUND_DOWN:
+	case ROUND_UP:
+		away = true
+	case ROUND_CEILING:
+		away = !neg
+	case ROUND_FLOOR:
+		away = neg
+	default:
+		r.Abs(r).Lsh(r, 1)
+		c := r.Cmp(new(big.Int).Abs(m))
+		away = c > 0 || c == 0 && (mode == ROUND_HALF_UP || q.Bit(0) == 1)
+	}
+	if away && neg {
+		q.Sub(q, big.NewInt(1))
+	} else if away {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+// Round returns d rounded to places decimal places by mode, or padded with
+// zeroes if d has fewer places. Negative places rounds to tens, hundreds and
+// so on, with zero scale.
+func (d Decimal) Round(places int, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		return Decimal{n: d.rescale(places), scale: places}
+	}
+	n := quoRound(d.unscaled(), pow10(d.scale-places), mode)
+	return makeDecimal(n, places)
+}
+
+// decimalInt64 returns d rounded half away from zero to integer, or false
+// if it overflows int64.
+func decimalInt64(d Decimal) (int64, bool) {
+	n := d.Round(0, ROUND_HALF_UP).unscaled()
+	return n.Int64(), n.IsInt64()
+}
+
+// String returns d in plain notation with all its decimal places, like
+// ``-123.450''. Trailing zeroes can be removed by TrimZero or replaced by
+// SpaceZero, and the digits grouped by FormatNumber.
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.unscaled()).String()
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+	if d.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// Float64 returns the nearest float of d.
+func (d Decimal) Float64() float64 {
+	x, _ := strconv.ParseFloat(d.String(), 64)
+	return x
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// All ParseDecimal formats are accepted.
+func (d *Decimal) UnmarshalText(b []byte) (err error) {
+	*d, err = ParseDecimal(string(b))
+	return
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The decimal is encoded as JSON_STRING, use DecimalJson for JSON_NUMBER.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Both string and number are accepted without losing precision.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	switch c := jsonKind(b); {
+	case c == 'n':
+		if bytes.Equal(bytes.TrimSpace(b), jsonNull) {
+			return nil
+		}
+	case c == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	case c == '-' || (c >= '0' && c <= '9'):
+		var n json.Number
+		if err := json.Unmarshal(b, &n); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(n))
+	}
+	return fmt.Errorf("invalid json for %T: %s", d, b)
+}
+
+// DecimalJson wraps Decimal to be encoded in Form JSON form, e.g.
+//     json.Marshal(DecimalJson{Decimal: d, Form: JSON_NUMBER})
+// JSON_NUMBER encodes the exact digits as number, other forms are encoded
+// as JSON_STRING. Both string and number are decoded like Decimal.
+type DecimalJson struct {
+	Decimal
+	Form JsonForm
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (j DecimalJson) MarshalJSON() ([]byte, error) {
+	if j.Form == JSON_NUMBER {
+		return []byte(j.String()), nil
+	}
+	return j.Decimal.MarshalJSON()
+}
+
+// Value implements the driver.Valuer interface.
+// The decimal is stored as String() output.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// It accepts string, []byte, int64 and float64 values.
+func (d *Decimal) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case string:
+		*d, err = ParseDecimal(v)
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case int64:
+		*d = MakeDecimal(v, 0)
+	case float64:
+		*d, err = FloatToDecimal(v)
+	default:
+		err = fmt.Errorf("cannot scan %T into %T", src, d)
+	}
+	return
+}
@@ -0,0 +1,249 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"math"
+	"math/big"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decimal", func() {
+	dec := func(s string) Decimal {
+		d, err := ParseDecimal(s)
+		Expect(err).To(Succeed())
+		return d
+	}
+
+	DescribeTable("ParseDecimal", func(s, x string, scale int) {
+		d := dec(s)
+		Expect(d.String()).To(Equal(x))
+		Expect(d.Scale()).To(Equal(scale))
+	},
+		Entry("integer", "123", "123", 0),
+		Entry("keep scale", "-123.450", "-123.450", 3),
+		Entry("plus", "+1.5", "1.5", 1),
+		Entry("leading point", ".05", "0.05", 2),
+		Entry("trailing point", "7.", "7", 0),
+		Entry("exponent", "1.5e-3", "0.0015", 4),
+		Entry("positive exponent", "1.25E2", "125", 0),
+		Entry("large exponent", "12e3", "12000", 0),
+		Entry("negative zero", "-0.00", "0.00", 2),
+		Entry("big", "123456789012345678901234567890.123456789",
+			"123456789012345678901234567890.123456789", 9),
+	)
+
+	DescribeTable("ParseDecimal error", func(s string) {
+		_, err := ParseDecimal(s)
+		Expect(err).To(MatchError(`invalid decimal: "` + s + `"`))
+	},
+		Entry("empty", ""),
+		Entry("point", "."),
+		Entry("letters", "1a"),
+		Entry("comma", "1,5"),
+		Entry("double sign", "--1"),
+		Entry("no exponent", "1e"),
+	)
+
+	DescribeTable("ParseDecimal scale limit", func(s string, ok bool) {
+		_, err := ParseDecimal(s)
+		if ok {
+			Expect(err).To(Succeed())
+		} else {
+			Expect(err).
+				To(MatchError(`decimal scale out of range: "` + s + `"`))
+		}
+	},
+		Entry("max", "1e-4096", true),
+		Entry("min", "1e4096", true),
+		Entry("small exponent", "1e-4097", false),
+		Entry("large exponent", "1e5000000", false),
+		Entry("huge exponent", "1e-999999999", false),
+		Entry("long fraction", "0."+strings.Repeat("0", 4096)+"1", false),
+		Entry("fraction and exponent", "0.5e-4096", false),
+	)
+
+	It("should make decimal", func() {
+		Expect(MakeDecimal(12345, 2).String()).To(Equal("123.45"))
+		Expect(MakeDecimal(-5, 3).String()).To(Equal("-0.005"))
+		Expect(MakeDecimal(12, -2).String()).To(Equal("1200"))
+		Expect(Decimal{}.String()).To(Equal("0"))
+		Expect(MakeDecimal(12345, 2).Unscaled()).To(Equal(big.NewInt(12345)))
+	})
+
+	It("should convert float", func() {
+		d, err := FloatToDecimal(1.005)
+		Expect(err).To(Succeed())
+		Expect(d.String()).To(Equal("1.005"))
+		Expect(d.Float64()).To(Equal(1.005))
+
+		_, err = FloatToDecimal(math.Inf(1))
+		Expect(err).To(MatchError("invalid decimal: +Inf"))
+	})
+
+	It("should calculate", func() {
+		a, b := dec("10.50"), dec("-0.125")
+		Expect(a.Add(b).String()).To(Equal("10.375"))
+		Expect(a.Sub(b).String()).To(Equal("10.625"))
+		Expect(a.Mul(b).String()).To(Equal("-1.31250"))
+		Expect(b.Neg().String()).To(Equal("0.125"))
+		Expect(b.Abs().String()).To(Equal("0.125"))
+		Expect(a.Sign()).To(Equal(1))
+		Expect(b.Sign()).To(Equal(-1))
+		Expect(Decimal{}.Add(a).String()).To(Equal("10.50"))
+		Expect(a.String()).To(Equal("10.50"))
+
+		sum := Decimal{}
+		for i := 0; i < 10; i++ {
+			sum = sum.Add(dec("0.1"))
+		}
+		Expect(sum.String()).To(Equal("1.0"))
+	})
+
+	It("should compare", func() {
+		Expect(dec("1.5").Cmp(dec("1.50"))).To(Equal(0))
+		Expect(dec("1.5").Equal(dec("1.50"))).To(BeTrue())
+		Expect(dec("1.49").Cmp(dec("1.5"))).To(Equal(-1))
+		Expect(dec("-1").Cmp(dec("-1.01"))).To(Equal(1))
+		Expect(dec("0.000").IsZero()).To(BeTrue())
+		Expect(Decimal{}.Equal(dec("0.0"))).To(BeTrue())
+	})
+
+	DescribeTable("Round", func(s string, mode RoundingMode, x string) {
+		Expect(dec(s).Round(1, mode).String()).To(Equal(x))
+	},
+		Entry("half up", "1.25", ROUND_HALF_UP, "1.3"),
+		Entry("half up negative", "-1.25", ROUND_HALF_UP, "-1.3"),
+		Entry("half up lower", "1.249", ROUND_HALF_UP, "1.2"),
+		Entry("half even down", "1.25", ROUND_HALF_EVEN, "1.2"),
+		Entry("half even up", "1.35", ROUND_HALF_EVEN, "1.4"),
+		Entry("half even above", "1.2501", ROUND_HALF_EVEN, "1.3"),
+		Entry("half even negative", "-1.25", ROUND_HALF_EVEN, "-1.2"),
+		Entry("down", "1.29", ROUND_DOWN, "1.2"),
+		Entry("down negative", "-1.29", ROUND_DOWN, "-1.2"),
+		Entry("up", "1.21", ROUND_UP, "1.3"),
+		Entry("up negative", "-1.21", ROUND_UP, "-1.3"),
+		Entry("ceiling", "1.21", ROUND_CEILING, "1.3"),
+		Entry("ceiling negative", "-1.29", ROUND_CEILING, "-1.2"),
+		Entry("floor", "1.29", ROUND_FLOOR, "1.2"),
+		Entry("floor negative", "-1.21", ROUND_FLOOR, "-1.3"),
+		Entry("exact", "1.20", ROUND_UP, "1.2"),
+		Entry("pad", "1", ROUND_UP, "1.0"),
+		Entry("carry", "9.96", ROUND_HALF_UP, "10.0"),
+		Entry("to zero", "-0.04", ROUND_HALF_UP, "0.0"),
+	)
+
+	It("should round to tens", func() {
+		Expect(dec("1250.5").Round(-2, ROUND_HALF_UP).String()).To(Equal("1300"))
+		Expect(dec("1250").Round(-2, ROUND_HALF_EVEN).String()).To(Equal("1200"))
+	})
+
+	DescribeTable("Quo", func(a, b string, scale int, mode RoundingMode,
+		x string) {
+		q, err := dec(a).Quo(dec(b), scale, mode)
+		Expect(err).To(Succeed())
+		Expect(q.String()).To(Equal(x))
+	},
+		Entry("exact", "10", "4", 2, ROUND_HALF_UP, "2.50"),
+		Entry("third", "1", "3", 4, ROUND_HALF_UP, "0.3333"),
+		Entry("two thirds", "2", "3", 4, ROUND_HALF_UP, "0.6667"),
+		Entry("negative", "-2", "3", 2, ROUND_DOWN, "-0.66"),
+		Entry("negative divisor", "2", "-3", 2, ROUND_FLOOR, "-0.67"),
+		Entry("scaled divisor", "1.000", "0.3", 1, ROUND_HALF_UP, "3.3"),
+		Entry("half even", "0.5", "2", 1, ROUND_HALF_EVEN, "0.2"),
+		Entry("hundreds", "12345", "1", -2, ROUND_HALF_UP, "12300"),
+		Entry("tens", "145", "10", -1, ROUND_HALF_UP, "10"),
+		Entry("tens half", "150", "10", -1, ROUND_HALF_EVEN, "20"),
+		Entry("tens scaled", "-14.5", "0.1", -2, ROUND_FLOOR, "-200"),
+	)
+
+	It("should not divide by zero", func() {
+		_, err := dec("1").Quo(dec("0.0"), 2, ROUND_HALF_UP)
+		Expect(err).To(MatchError("decimal division by zero"))
+	})
+
+	It("should format", func() {
+		d := dec("1234567.500")
+		Expect(TrimZero(d.String())).To(Equal("1234567.5"))
+		Expect(SpaceZero(d.String())).To(Equal("1234567.5  "))
+		Expect(FormatNumber(d, "id")).To(Equal("1.234.567,500"))
+		Expect(FormatMoney(d, RUPIAH, "id")).To(Equal("Rp1.234.567,50"))
+	})
+
+	Context("encoding", func() {
+		type obj struct {
+			D Decimal `json:"d"`
+		}
+
+		It("should marshal text", func() {
+			b, err := dec("-1.50").MarshalText()
+			Expect(err).To(Succeed())
+			Expect(string(b)).To(Equal("-1.50"))
+
+			var d Decimal
+			Expect(d.UnmarshalText([]byte("2.0"))).To(Succeed())
+			Expect(d.String()).To(Equal("2.0"))
+			Expect(d.UnmarshalText([]byte("x"))).
+				To(MatchError(`invalid decimal: "x"`))
+		})
+
+		It("should marshal JSON", func() {
+			Expect(json.Marshal(obj{dec("12.340")})).
+				To(Equal([]byte(`{"d":"12.340"}`)))
+
+			j := DecimalJson{Decimal: dec("12.340"), Form: JSON_NUMBER}
+			Expect(json.Marshal(j)).To(Equal([]byte(`12.340`)))
+			j.Form = JSON_STRING
+			Expect(json.Marshal(j)).To(Equal([]byte(`"12.340"`)))
+		})
+
+		It("should unmarshal JSON by wrapper", func() {
+			var j DecimalJson
+			Expect(json.Unmarshal([]byte(`-0.50`), &j)).To(Succeed())
+			Expect(j.String()).To(Equal("-0.50"))
+		})
+
+		DescribeTable("UnmarshalJSON", func(j, x string) {
+			var o obj
+			Expect(json.Unmarshal([]byte(j), &o)).To(Succeed())
+			Expect(o.D.String()).To(Equal(x))
+		},
+			Entry("string", `{"d":"12.340"}`, "12.340"),
+			Entry("number", `{"d":12345678901234567890.12}`,
+				"12345678901234567890.12"),
+			Entry("negative", `{"d":-1e-2}`, "-0.01"),
+			Entry("null", `{"d":null}`, "0"),
+		)
+
+		It("should not unmarshal other JSON", func() {
+			var d Decimal
+			Expect(d.UnmarshalJSON([]byte(`true`))).
+				To(MatchError("invalid json for *util.Decimal: true"))
+		})
+
+		It("should be SQL value", func() {
+			Expect(dec("1.50").Value()).To(Equal("1.50"))
+		})
+
+		DescribeTable("Scan", func(src interface{}, x string) {
+			var d Decimal
+			Expect(d.Scan(src)).To(Succeed())
+			Expect(d.String()).To(Equal(x))
+		},
+			Entry("string", "1.50", "1.50"),
+			Entry("bytes", []byte("-0.25"), "-0.25"),
+			Entry("int64", int64(42), "42"),
+			Entry("float64", 1.005, "1.005"),
+		)
+
+		It("should not scan other type", func() {
+			var d Decimal
+			Expect(d.Scan(true)).
+				To(MatchError("cannot scan bool into *util.Decimal"))
+		})
+	})
+})
@@ -0,0 +1,204 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nominal durations of a day and a week, ignoring daylight saving time.
+const (
+	DAY  = 24 * time.Hour
+	WEEK = 7 * DAY
+)
+
+// durationUnits are the units accepted by ParseDuration.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond, // U+00B5 micro sign
+	"μs": time.Microsecond, // U+03BC Greek letter mu
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  DAY,
+	"w":  WEEK,
+}
+
+// absDuration returns the absolute value of d in nanoseconds, and its sign
+// prefix. The minimum duration doesn't overflow.
+func absDuration(d time.Duration) (uint64, string) {
+	if d < 0 {
+		return -uint64(d), "-"
+	}
+	return uint64(d), ""
+}
+
+// formatSeconds returns ns nanoseconds as seconds with fraction, like
+// ``4.5''.
+func formatSeconds(ns uint64) string {
+	s := strconv.FormatUint(ns/uint64(time.Second), 10)
+	if frac := ns % uint64(time.Second); frac != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", frac), "0")
+	}
+	return s
+}
+
+// FormatDuration returns compact duration with day unit, like ``1d2h5m'' or
+// ``3m4.5s''. Non zero units rounds the duration half away from zero to
+// show at most that many units, e.g. ``1d2h'' of 2 units. Duration under a
+// second is formatted by time.Duration String(), like ``250ms''.
+func FormatDuration(d time.Duration, units int) string {
+	u, sign := absDuration(d)
+	if u < uint64(time.Second) {
+		return d.String()
+	}
+
+	names := []string{"d", "h", "m"}
+	sizes := []uint64{uint64(DAY), uint64(time.Hour), uint64(time.Minute),
+		uint64(time.Second)}
+	first := 0
+	for u < sizes[first] {
+		first++
+	}
+	if units > 0 && first+units < len(sizes) {
+		size := sizes[first+units-1]
+		u = (u + size/2) / size * size
+	}
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for i, name := range names {
+		if n := u / sizes[i]; n > 0 {
+			sb.WriteString(strconv.FormatUint(n, 10) + name)
+		}
+		u %= sizes[i]
+	}
+	if u > 0 {
+		sb.WriteString(formatSeconds(u) + "s")
+	}
+	return sb.String()
+}
+
+// FormatClock returns duration in clock style rounded to seconds, like
+// ``02:03:04'', prefixed by the days if any, like ``1d 02:03:04''.
+func FormatClock(d time.Duration) string {
+	u, sign := absDuration(d)
+	sec := (u + uint64(time.Second)/2) / uint64(time.Second)
+	s := fmt.Sprintf("%02d:%02d:%02d", sec/3600%24, sec/60%60, sec%60)
+	if days := sec / 86400; days > 0 {
+		s = strconv.FormatUint(days, 10) + "d " + s
+	}
+	return sign + s
+}
+
+// FormatISODuration returns ISO 8601 duration, like ``P1DT2H3M4.5S''. A day
+// is always 24 hours, and zero duration is ``PT0S''.
+func FormatISODuration(d time.Duration) string {
+	u, sign := absDuration(d)
+	if u == 0 {
+		return "PT0S"
+	}
+	s := sign + "P"
+	if days := u / uint64(DAY); days > 0 {
+		s += strconv.FormatUint(days, 10) + "D"
+	}
+	u %= uint64(DAY)
+	if u == 0 {
+		return s
+	}
+	s += "T"
+	if h := u / uint64(time.Hour); h > 0 {
+		s += strconv.FormatUint(h, 10) + "H"
+	}
+	if m := u / uint64(time.Minute) % 60; m > 0 {
+		s += strconv.FormatUint(m, 10) + "M"
+	}
+	if ns := u % uint64(time.Minute); ns > 0 {
+		s += formatSeconds(ns) + "S"
+	}
+	return s
+}
+
+//============================================================================
+
+var (
+	durationUnitRE = regexp.MustCompile(
+		`^(\d+(?:\.\d*)?|\.\d+)\s*(ns|us|µs|μs|ms|s|m|h|d|w)\s*`)
+	durationClockRE = regexp.MustCompile(
+		`^(?:(\d+)d\s*)?(\d+):([0-5]\d)(?::([0-5]\d(?:\.\d{1,9})?))?$`)
+	durationISORE = regexp.MustCompile(`^P(?:(\d+(?:[.,]\d+)?)W)?` +
+		`(?:(\d+(?:[.,]\d+)?)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?` +
+		`(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+)
+
+// ParseDuration parses duration in any of these formats, with optional sign:
+//   - time.ParseDuration format with ``d'' day and ``w'' week units, and
+//     optional spaces between the components, like ``1w2d'' or ``1d 2h5m''.
+//   - Clock style, like ``02:03:04'', ``02:03'' or ``1d 02:03:04.5''.
+//   - ISO 8601 duration of weeks, days and time, like ``P1DT2H'', years
+//     and months are not accepted because their lengths vary.
+// A day is always 24 hours.
+func ParseDuration(s string) (time.Duration, error) {
+	t := strings.TrimSpace(s)
+	neg := strings.HasPrefix(t, "-")
+	if neg || strings.HasPrefix(t, "+") {
+		t = t[1:]
+	}
+
+	var parts []string
+	var units []time.Duration
+	switch {
+	case t == "":
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	case strings.HasPrefix(t, "P"):
+		m := durationISORE.FindStringSubmatch(t)
+		if m == nil || t == "P" || strings.HasSuffix(t, "T") {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		parts = m[1:]
+		units = []time.Duration{WEEK, DAY, time.Hour, time.Minute,
+			time.Second}
+	case strings.Contains(t, ":"):
+		m := durationClockRE.FindStringSubmatch(t)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		parts = m[1:]
+		units = []time.Duration{DAY, time.Hour, time.Minute, time.Second}
+	case t == "0":
+	default:
+		for t != "" {
+			m := durationUnitRE.FindStringSubmatch(t)
+			if m == nil {
+				return 0, fmt.Errorf("invalid duration: %q", s)
+			}
+			parts = append(parts, m[1])
+			units = append(units, durationUnits[m[2]])
+			t = t[len(m[0]):]
+		}
+	}
+
+	var total Decimal
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		n, err := ParseDecimal(strings.Replace(p, ",", ".", 1))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+		total = total.Add(n.Mul(MakeDecimal(int64(units[i]), 0)))
+	}
+	if neg {
+		total = total.Neg()
+	}
+	ns, ok := decimalInt64(total)
+	if !ok {
+		return 0, fmt.Errorf("duration out of range: %q", s)
+	}
+	return time.Duration(ns), nil
+}
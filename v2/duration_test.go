@@ -0,0 +1,111 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duration", func() {
+	dhms := func(d, h, m int, s float64) time.Duration {
+		return time.Duration(d)*DAY + time.Duration(h)*time.Hour +
+			time.Duration(m)*time.Minute +
+			time.Duration(s*float64(time.Second))
+	}
+
+	DescribeTable("FormatDuration", func(d time.Duration, units int, x string) {
+		Expect(FormatDuration(d, units)).To(Equal(x))
+	},
+		Entry("zero", time.Duration(0), 0, "0s"),
+		Entry("sub second", 250*time.Millisecond, 0, "250ms"),
+		Entry("seconds", dhms(0, 0, 0, 4.5), 0, "4.5s"),
+		Entry("all", dhms(1, 2, 5, 4), 0, "1d2h5m4s"),
+		Entry("skip zero", dhms(0, 2, 5, 0), 0, "2h5m"),
+		Entry("nanosecond", dhms(0, 1, 0, 0)+1, 0, "1h0.000000001s"),
+		Entry("weeks as days", 2*WEEK, 0, "14d"),
+		Entry("negative", -dhms(0, 0, 3, 4.5), 0, "-3m4.5s"),
+		Entry("two units", dhms(1, 2, 5, 40), 2, "1d2h"),
+		Entry("round up", dhms(0, 2, 5, 40), 2, "2h6m"),
+		Entry("carry", dhms(0, 23, 59, 40), 2, "1d"),
+		Entry("one unit", dhms(0, 0, 3, 4.5), 1, "3m"),
+		Entry("enough units", dhms(0, 0, 3, 4.5), 2, "3m4.5s"),
+		Entry("minimum", time.Duration(math.MinInt64), 0,
+			"-106751d23h47m16.854775808s"),
+	)
+
+	DescribeTable("FormatClock", func(d time.Duration, x string) {
+		Expect(FormatClock(d)).To(Equal(x))
+	},
+		Entry("zero", time.Duration(0), "00:00:00"),
+		Entry("time", dhms(0, 2, 3, 4), "02:03:04"),
+		Entry("round", dhms(0, 2, 3, 4.5), "02:03:05"),
+		Entry("days", dhms(1, 2, 3, 4), "1d 02:03:04"),
+		Entry("negative", -dhms(0, 0, 0, 5), "-00:00:05"),
+	)
+
+	DescribeTable("FormatISODuration", func(d time.Duration, x string) {
+		Expect(FormatISODuration(d)).To(Equal(x))
+	},
+		Entry("zero", time.Duration(0), "PT0S"),
+		Entry("all", dhms(1, 2, 3, 4.5), "P1DT2H3M4.5S"),
+		Entry("days", 3*DAY, "P3D"),
+		Entry("hours", dhms(0, 2, 0, 0), "PT2H"),
+		Entry("seconds", dhms(0, 0, 0, 0.25), "PT0.25S"),
+		Entry("negative", -dhms(1, 0, 30, 0), "-P1DT30M"),
+	)
+
+	DescribeTable("ParseDuration", func(s string, x time.Duration) {
+		Expect(ParseDuration(s)).To(Equal(x))
+	},
+		Entry("go", "2h5m", dhms(0, 2, 5, 0)),
+		Entry("go fraction", "1.5h", dhms(0, 1, 30, 0)),
+		Entry("sub second", "1ms500us", 1500*time.Microsecond),
+		Entry("micro sign", "3µs", 3*time.Microsecond),
+		Entry("days", "1d 2h5m", dhms(1, 2, 5, 0)),
+		Entry("weeks", "1w2d", dhms(9, 0, 0, 0)),
+		Entry("spaces", " 1 d 2 h ", dhms(1, 2, 0, 0)),
+		Entry("zero", "0", time.Duration(0)),
+		Entry("negative", "-1.5d", -dhms(1, 12, 0, 0)),
+		Entry("clock", "02:03:04", dhms(0, 2, 3, 4)),
+		Entry("clock minutes", "02:03", dhms(0, 2, 3, 0)),
+		Entry("clock hours", "100:00:00", dhms(4, 4, 0, 0)),
+		Entry("clock days", "1d 02:03:04.5", dhms(1, 2, 3, 4.5)),
+		Entry("clock negative", "-00:00:05", -dhms(0, 0, 0, 5)),
+		Entry("iso", "P1DT2H", dhms(1, 2, 0, 0)),
+		Entry("iso weeks", "P2W", 2*WEEK),
+		Entry("iso time", "PT3M4.5S", dhms(0, 0, 3, 4.5)),
+		Entry("iso comma", "PT0,5S", dhms(0, 0, 0, 0.5)),
+		Entry("iso negative", "-P1D", -DAY),
+		Entry("iso zero", "PT0S", time.Duration(0)),
+	)
+
+	DescribeTable("ParseDuration roundtrip", func(d time.Duration) {
+		Expect(ParseDuration(FormatDuration(d, 0))).To(Equal(d))
+		Expect(ParseDuration(FormatISODuration(d))).To(Equal(d))
+	},
+		Entry("zero", time.Duration(0)),
+		Entry("all", dhms(1, 2, 3, 4.5)),
+		Entry("negative", -dhms(0, 0, 3, 0.000001)),
+		Entry("minimum", time.Duration(math.MinInt64)),
+	)
+
+	DescribeTable("ParseDuration error", func(s, x string) {
+		_, err := ParseDuration(s)
+		Expect(err).To(MatchError(x))
+	},
+		Entry("empty", "", `invalid duration: ""`),
+		Entry("sign", "-", `invalid duration: "-"`),
+		Entry("no unit", "12", `invalid duration: "12"`),
+		Entry("unknown unit", "1y", `invalid duration: "1y"`),
+		Entry("clock", "1:60", `invalid duration: "1:60"`),
+		Entry("iso empty", "P", `invalid duration: "P"`),
+		Entry("iso empty time", "P1DT", `invalid duration: "P1DT"`),
+		Entry("iso year", "P1Y", `invalid duration: "P1Y"`),
+		Entry("iso month", "P1M", `invalid duration: "P1M"`),
+		Entry("out of range", "20000w", `duration out of range: "20000w"`),
+	)
+})
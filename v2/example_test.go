@@ -111,3 +111,43 @@ func ExampleTrimZero() {
 	fmt.Println(TrimZero("a:12.00 b:34.5000 c:6.780"))
 	// Output: a:12 b:34.5 c:6.78
 }
+
+func ExampleHexDump() {
+	fmt.Println(HexDump([]byte("Hello, world!\n\x00\x01\x02")))
+	// Output:
+	// 00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 0a 00 01  |Hello, world!...|
+	// 00000010  02                                                |.|
+}
+
+func ExampleHexDumper() {
+	frame := []byte{0x02, 0x10, 0x00, 0x05, 'P', 'I', 'N', 'G'}
+	frame = append(frame, make([]byte, 20)...)
+	frame = append(frame, 0x5A, 0x03)
+	h := HexDumper{
+		Width:   8,
+		Group:   4,
+		Offset:  0x100,
+		Upper:   true,
+		Squeeze: true,
+	}
+	fmt.Println(h.Dump(frame))
+	// Output:
+	// 00000100  02 10 00 05  50 49 4E 47  |....PING|
+	// 00000108  00 00 00 00  00 00 00 00  |........|
+	// *
+	// 00000118  00 00 00 00  5A 03        |....Z.|
+	// 0000011E
+}
+
+func ExampleParseHex() {
+	b, err := ParseHex(".. .1 a. 23")
+	fmt.Printf("% x %v\n", b, err)
+	b, err = ParseHexWrap("I raw rx: [\n  .f 1. 11\n]", "raw rx: [", "]")
+	fmt.Printf("% x %v\n", b, err)
+	_, err = ParseHex(".. .1\n.g")
+	fmt.Println(err)
+	// Output:
+	// 00 01 a0 23 <nil>
+	// 0f 10 11 <nil>
+	// invalid hex character 'g' at line 2 column 2
+}
@@ -0,0 +1,470 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Default maximum decoded frame size when MaxFrame is zero.
+const FRAME_MAX_DEFAULT = 4096
+
+// Special bytes of SLIP (RFC 1055) and STX/ETX framing.
+const (
+	SLIP_END     = 0xC0
+	SLIP_ESC     = 0xDB
+	SLIP_ESC_END = 0xDC
+	SLIP_ESC_ESC = 0xDD
+
+	STX = 0x02
+	ETX = 0x03
+	DLE = 0x10
+)
+
+// Framing splits byte stream into frames.
+type Framing interface {
+	// AppendFrame appends the encoded p frame to b.
+	AppendFrame(b, p []byte) ([]byte, error)
+	// Splitter returns new bufio.SplitFunc whose tokens are the decoded
+	// frames. Garbage between frames and invalid frames are skipped and
+	// passed to discard, which may be nil, so the stream resyncs at the next
+	// frame. The split function is stateful and must be used by a single
+	// bufio.Scanner.
+	Splitter(discard func(*FrameError)) bufio.SplitFunc
+}
+
+// FrameError describes skipped garbage or invalid frame. Data is only valid
+// during the discard call.
+type FrameError struct {
+	Msg  string
+	Data []byte
+}
+
+// Error returns the error message, e.g.
+//     invalid escape: 5 bytes
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("%s: %d bytes", e.Msg, len(e.Data))
+}
+
+func frameMax(n int) int {
+	if n <= 0 {
+		return FRAME_MAX_DEFAULT
+	}
+	return n
+}
+
+func checkFrame(p []byte, max int) error {
+	if len(p) > frameMax(max) {
+		return fmt.Errorf("frame length %d exceeds %d", len(p), frameMax(max))
+	}
+	return nil
+}
+
+// delimSplit returns split function of frames terminated by delim byte.
+// Encoded frames longer than raw bytes are discarded up to the next delim.
+func delimSplit(delim byte, raw, max int, decode func([]byte) ([]byte, error),
+	discard func(*FrameError)) bufio.SplitFunc {
+	report := func(b []byte, msg string) {
+		if discard != nil {
+			discard(&FrameError{Msg: msg, Data: b})
+		}
+	}
+	drop := false
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		i := bytes.IndexByte(data, delim)
+		if i < 0 {
+			if len(data) > raw || (atEOF && len(data) > 0) {
+				if !drop && len(data) > raw {
+					report(data, "frame too long")
+				} else if !drop {
+					report(data, "truncated frame")
+				}
+				drop = !atEOF
+				return len(data), nil, nil
+			}
+			return 0, nil, nil
+		}
+		if drop {
+			drop = false
+			return i + 1, nil, nil
+		}
+		if i == 0 {
+			return 1, nil, nil
+		}
+		p, err := decode(data[:i])
+		if err == nil && len(p) > max {
+			err = fmt.Errorf("frame too long")
+		}
+		if err != nil {
+			report(data[:i+1], err.Error())
+			return i + 1, nil, nil
+		}
+		return i + 1, p, nil
+	}
+}
+
+//============================================================================
+
+// SLIP is RFC 1055 framing. Each frame is terminated by SLIP_END, and
+// SLIP_END or SLIP_ESC in the frame are escaped. Empty frames are skipped.
+type SLIP struct {
+	// Maximum decoded frame size, zero is FRAME_MAX_DEFAULT
+	MaxFrame int
+}
+
+// AppendFrame appends the SLIP encoded p to b.
+func (s SLIP) AppendFrame(b, p []byte) ([]byte, error) {
+	if err := checkFrame(p, s.MaxFrame); err != nil {
+		return b, err
+	}
+	for _, c := range p {
+		switch c {
+		case SLIP_END:
+			b = append(b, SLIP_ESC, SLIP_ESC_END)
+		case SLIP_ESC:
+			b = append(b, SLIP_ESC, SLIP_ESC_ESC)
+		default:
+			b = append(b, c)
+		}
+	}
+	return append(b, SLIP_END), nil
+}
+
+// Splitter returns bufio.SplitFunc of decoded SLIP frames.
+func (s SLIP) Splitter(discard func(*FrameError)) bufio.SplitFunc {
+	max := frameMax(s.MaxFrame)
+	return delimSplit(SLIP_END, 2*max, max, func(b []byte) ([]byte, error) {
+		p := make([]byte, 0, len(b))
+		for i := 0; i < len(b); i++ {
+			c := b[i]
+			if c == SLIP_ESC {
+				if i++; i == len(b) {
+					return nil, fmt.Errorf("invalid escape")
+				}
+				switch b[i] {
+				case SLIP_ESC_END:
+					c = SLIP_END
+				case SLIP_ESC_ESC:
+					c = SLIP_ESC
+				default:
+					return nil, fmt.Errorf("invalid escape")
+				}
+			}
+			p = append(p, c)
+		}
+		return p, nil
+	}, discard)
+}
+
+//============================================================================
+
+// COBS is Consistent Overhead Byte Stuffing framing, each frame is encoded
+// without zero bytes and terminated by a zero byte.
+type COBS struct {
+	// Maximum decoded frame size, zero is FRAME_MAX_DEFAULT
+	MaxFrame int
+}
+
+// AppendFrame appends the COBS encoded p to b.
+func (c COBS) AppendFrame(b, p []byte) ([]byte, error) {
+	if err := checkFrame(p, c.MaxFrame); err != nil {
+		return b, err
+	}
+	ci := len(b)
+	b = append(b, 0)
+	code := byte(1)
+	for i, x := range p {
+		if x != 0 {
+			b = append(b, x)
+			code++
+		}
+		// full block opens the next one only if more input follows
+		if x == 0 || code == 0xFF && i < len(p)-1 {
+			b[ci] = code
+			ci = len(b)
+			b = append(b, 0)
+			code = 1
+		}
+	}
+	b[ci] = code
+	return append(b, 0), nil
+}
+
+// Splitter returns bufio.SplitFunc of decoded COBS frames.
+func (c COBS) Splitter(discard func(*FrameError)) bufio.SplitFunc {
+	max := frameMax(c.MaxFrame)
+	return delimSplit(0, max+max/254+1, max, func(b []byte) ([]byte, error) {
+		p := make([]byte, 0, len(b))
+		for i := 0; i < len(b); {
+			code := int(b[i])
+			i++
+			if i+code-1 > len(b) {
+				return nil, fmt.Errorf("invalid COBS code")
+			}
+			p = append(p, b[i:i+code-1]...)
+			i += code - 1
+			if code < 0xFF && i < len(b) {
+				p = append(p, 0)
+			}
+		}
+		return p, nil
+	}, discard)
+}
+
+//============================================================================
+
+// STXETX is STX ... ETX framing, STX, ETX and DLE in the frame are escaped
+// by DLE prefix. Bytes outside STX and ETX are garbage.
+type STXETX struct {
+	// Maximum decoded frame size, zero is FRAME_MAX_DEFAULT
+	MaxFrame int
+}
+
+// AppendFrame appends the STX/ETX encoded p to b.
+func (s STXETX) AppendFrame(b, p []byte) ([]byte, error) {
+	if err := checkFrame(p, s.MaxFrame); err != nil {
+		return b, err
+	}
+	b = append(b, STX)
+	for _, c := range p {
+		if c == STX || c == ETX || c == DLE {
+			b = append(b, DLE)
+		}
+		b = append(b, c)
+	}
+	return append(b, ETX), nil
+}
+
+// Splitter returns bufio.SplitFunc of decoded STX/ETX frames.
+func (s STXETX) Splitter(discard func(*FrameError)) bufio.SplitFunc {
+	max := frameMax(s.MaxFrame)
+	report := func(b []byte, msg string) {
+		if discard != nil {
+			discard(&FrameError{Msg: msg, Data: b})
+		}
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		start := bytes.IndexByte(data, STX)
+		if start < 0 {
+			start = len(data)
+		}
+		if start > 0 {
+			report(data[:start], "garbage")
+			return start, nil, nil
+		}
+
+		p := []byte{}
+		for j := 1; j < len(data); j++ {
+			switch c := data[j]; c {
+			case DLE:
+				if j+1 == len(data) {
+					// wait for the escaped byte
+					break
+				}
+				j++
+				if c = data[j]; c != STX && c != ETX && c != DLE {
+					report(data[:j+1], "invalid escape")
+					return j + 1, nil, nil
+				}
+				p = append(p, c)
+			case STX:
+				report(data[:j], "truncated frame")
+				return j, nil, nil
+			case ETX:
+				return j + 1, p, nil
+			default:
+				p = append(p, c)
+			}
+			if len(p) > max {
+				report(data[:j+1], "frame too long")
+				return j + 1, nil, nil
+			}
+		}
+		if atEOF && len(data) > 0 {
+			report(data, "truncated frame")
+			return len(data), nil, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+//============================================================================
+
+// LengthPrefix is length prefixed framing. The length can't be validated,
+// so an invalid length is a fatal error without resync.
+type LengthPrefix struct {
+	// Prefix size in bytes from 1 to 8, zero is 2
+	Size int
+	// Byte order of the prefix, nil is binary.BigEndian
+	ByteOrder binary.ByteOrder
+	// Maximum frame size, zero is FRAME_MAX_DEFAULT
+	MaxFrame int
+}
+
+func (l LengthPrefix) size() int {
+	if l.Size <= 0 {
+		return 2
+	}
+	return l.Size
+}
+
+func (l LengthPrefix) max() int {
+	max := frameMax(l.MaxFrame)
+	if n := l.size(); n < 8 && max > 1<<(8*n)-1 {
+		max = 1<<(8*n) - 1
+	}
+	return max
+}
+
+// AppendFrame appends the length prefix and p to b.
+func (l LengthPrefix) AppendFrame(b, p []byte) ([]byte, error) {
+	if l.size() > 8 {
+		return b, fmt.Errorf("invalid length prefix size: %d", l.size())
+	}
+	if err := checkFrame(p, l.max()); err != nil {
+		return b, err
+	}
+	b = putUint(b, uint64(len(p)), l.size(), l.ByteOrder == binary.LittleEndian)
+	return append(b, p...), nil
+}
+
+// Splitter returns bufio.SplitFunc of length prefixed frames, discard is
+// not used.
+func (l LengthPrefix) Splitter(discard func(*FrameError)) bufio.SplitFunc {
+	n, max := l.size(), l.max()
+	le := l.ByteOrder == binary.LittleEndian
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if n > 8 {
+			return 0, nil, fmt.Errorf("invalid length prefix size: %d", n)
+		}
+		if len(data) >= n {
+			size := getUint(data[:n], le)
+			if size > uint64(max) {
+				return 0, nil, fmt.Errorf("frame length %d exceeds %d",
+					size, max)
+			}
+			if end := n + int(size); len(data) >= end {
+				return end, data[n:end], nil
+			}
+		}
+		if atEOF && len(data) > 0 {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return 0, nil, nil
+	}
+}
+
+//============================================================================
+
+// FrameReader reads frames from io.Reader.
+type FrameReader struct {
+	// Optional hook called with each decoded frame
+	OnFrame func(frame []byte)
+	// Optional hook called with skipped garbage and invalid frames
+	OnDiscard func(err *FrameError)
+
+	s    *bufio.Scanner
+	rest []byte
+}
+
+// NewFrameReader returns FrameReader of r stream framed by f.
+func NewFrameReader(r io.Reader, f Framing) *FrameReader {
+	fr := &FrameReader{s: bufio.NewScanner(r)}
+	fr.s.Buffer(nil, math.MaxInt32)
+	fr.s.Split(f.Splitter(func(e *FrameError) {
+		if fr.OnDiscard != nil {
+			fr.OnDiscard(e)
+		}
+	}))
+	return fr
+}
+
+// ReadFrame returns the next frame, or io.EOF at the end of stream.
+func (r *FrameReader) ReadFrame() ([]byte, error) {
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	p := append([]byte{}, r.s.Bytes()...)
+	if r.OnFrame != nil {
+		r.OnFrame(p)
+	}
+	return p, nil
+}
+
+// Read implements io.Reader, it reads at most one frame at a time. A frame
+// longer than p is returned by the following reads. Empty frames are
+// skipped, use ReadFrame to receive them.
+func (r *FrameReader) Read(p []byte) (int, error) {
+	for len(r.rest) == 0 {
+		f, err := r.ReadFrame()
+		if err != nil {
+			return 0, err
+		}
+		r.rest = f
+	}
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+	return n, nil
+}
+
+// FrameWriter writes frames to io.Writer.
+type FrameWriter struct {
+	// Optional hook called with each frame before it is encoded
+	OnFrame func(frame []byte)
+
+	w   io.Writer
+	f   Framing
+	buf []byte
+}
+
+// NewFrameWriter returns FrameWriter to w stream framed by f.
+func NewFrameWriter(w io.Writer, f Framing) *FrameWriter {
+	return &FrameWriter{w: w, f: f}
+}
+
+// WriteFrame encodes and writes p as a single write to the stream.
+func (w *FrameWriter) WriteFrame(p []byte) error {
+	if w.OnFrame != nil {
+		w.OnFrame(p)
+	}
+	b, err := w.f.AppendFrame(w.buf[:0], p)
+	if err != nil {
+		return err
+	}
+	w.buf = b
+	_, err = w.w.Write(b)
+	return err
+}
+
+// Write implements io.Writer, each write is a frame.
+func (w *FrameWriter) Write(p []byte) (int, error) {
+	if err := w.WriteFrame(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// FrameLogger returns OnFrame hook that logs each frame using logf, like
+// log.Printf, formatted by HexWrapper like ``rx [.2 .1 ff]''.
+func FrameLogger(logf func(string, ...interface{}), prefix string) func(
+	[]byte) {
+	return func(b []byte) {
+		logf("%v", HexWrapper(b, prefix+" [", "]", "    ", 80, 80))
+	}
+}
+
+// DiscardLogger returns OnDiscard hook that logs each discarded data using
+// logf, formatted by HexWrapper like ``rx garbage [.1 .2]''.
+func DiscardLogger(logf func(string, ...interface{}), prefix string) func(
+	*FrameError) {
+	return func(e *FrameError) {
+		b := append([]byte{}, e.Data...)
+		logf("%v", HexWrapper(b, prefix+" "+e.Msg+" [", "]", "    ", 80, 80))
+	}
+}
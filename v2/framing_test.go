@@ -0,0 +1,246 @@
+package util_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	. "github.com/hanindo/util/v2"
+	"io"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// readFrames returns all frames and discarded data of encoded stream, read
+// one byte at a time.
+func readFrames(f Framing, encoded []byte) ([][]byte, []string, error) {
+	r := NewFrameReader(iotest.OneByteReader(bytes.NewReader(encoded)), f)
+	var discards []string
+	r.OnDiscard = func(e *FrameError) {
+		discards = append(discards, e.Msg+" "+FancyHex(e.Data))
+	}
+	var frames [][]byte
+	for {
+		p, err := r.ReadFrame()
+		if err == io.EOF {
+			return frames, discards, nil
+		} else if err != nil {
+			return frames, discards, err
+		}
+		frames = append(frames, p)
+	}
+}
+
+var _ = Describe("Framing", func() {
+	frames := [][]byte{
+		{0x01, 0x02},
+		{},
+		{0xC0, 0xDB, 0x00, 0x02, 0x03, 0x10, 0xFF},
+		bytes.Repeat([]byte{0x55}, 300),
+	}
+
+	DescribeTable("roundtrip", func(f Framing, skipEmpty bool) {
+		var buf bytes.Buffer
+		w := NewFrameWriter(&buf, f)
+		for _, p := range frames {
+			Expect(w.WriteFrame(p)).To(Succeed())
+		}
+
+		expected := frames
+		if skipEmpty {
+			expected = [][]byte{frames[0], frames[2], frames[3]}
+		}
+		got, discards, err := readFrames(f, buf.Bytes())
+		Expect(err).To(Succeed())
+		Expect(discards).To(BeEmpty())
+		Expect(got).To(Equal(expected))
+	},
+		Entry("SLIP", SLIP{}, true),
+		Entry("COBS", COBS{}, false),
+		Entry("STXETX", STXETX{}, false),
+		Entry("LengthPrefix", LengthPrefix{}, false),
+		Entry("LengthPrefix le", LengthPrefix{Size: 4,
+			ByteOrder: binary.LittleEndian}, false),
+	)
+
+	DescribeTable("encode", func(f Framing, p, encoded []byte) {
+		b, err := f.AppendFrame([]byte{0xAA}, p)
+		Expect(err).To(Succeed())
+		Expect(b).To(EqualBytes(append([]byte{0xAA}, encoded...)))
+	},
+		Entry("SLIP", SLIP{}, []byte{0x01, 0xC0, 0xDB},
+			[]byte{0x01, 0xDB, 0xDC, 0xDB, 0xDD, 0xC0}),
+		Entry("COBS", COBS{}, []byte{0x11, 0x22, 0x00, 0x33},
+			[]byte{0x03, 0x11, 0x22, 0x02, 0x33, 0x00}),
+		Entry("COBS zero", COBS{}, []byte{0x00},
+			[]byte{0x01, 0x01, 0x00}),
+		Entry("COBS empty", COBS{}, []byte{}, []byte{0x01, 0x00}),
+		Entry("COBS 254", COBS{}, bytes.Repeat([]byte{0x01}, 254),
+			append(append([]byte{0xFF}, bytes.Repeat([]byte{0x01}, 254)...),
+				0x00)),
+		Entry("COBS 255", COBS{}, bytes.Repeat([]byte{0x01}, 255),
+			append(append([]byte{0xFF}, bytes.Repeat([]byte{0x01}, 254)...),
+				0x02, 0x01, 0x00)),
+		Entry("COBS 254 zero", COBS{},
+			append(bytes.Repeat([]byte{0x01}, 254), 0x00),
+			append(append([]byte{0xFF}, bytes.Repeat([]byte{0x01}, 254)...),
+				0x01, 0x01, 0x00)),
+		Entry("STXETX", STXETX{}, []byte{0x01, 0x02, 0x10},
+			[]byte{0x02, 0x01, 0x10, 0x02, 0x10, 0x10, 0x03}),
+		Entry("LengthPrefix", LengthPrefix{Size: 1}, []byte{0x01, 0x02},
+			[]byte{0x02, 0x01, 0x02}),
+	)
+
+	It("should roundtrip COBS block boundaries", func() {
+		for _, n := range []int{253, 254, 255, 508, 509} {
+			p := bytes.Repeat([]byte{0x01}, n)
+			for _, frame := range [][]byte{p, append(p, 0x00)} {
+				b, err := COBS{}.AppendFrame(nil, frame)
+				Expect(err).To(Succeed())
+				r := NewFrameReader(bytes.NewReader(b), COBS{})
+				Expect(r.ReadFrame()).To(Equal(frame), "%d", len(frame))
+			}
+		}
+	})
+
+	DescribeTable("max frame", func(f Framing, msg string) {
+		_, err := f.AppendFrame(nil, make([]byte, 5))
+		Expect(err).To(MatchError(msg))
+	},
+		Entry("SLIP", SLIP{MaxFrame: 4}, "frame length 5 exceeds 4"),
+		Entry("COBS", COBS{MaxFrame: 4}, "frame length 5 exceeds 4"),
+		Entry("STXETX", STXETX{MaxFrame: 4}, "frame length 5 exceeds 4"),
+		Entry("LengthPrefix", LengthPrefix{MaxFrame: 4},
+			"frame length 5 exceeds 4"),
+		Entry("LengthPrefix size", LengthPrefix{Size: 9},
+			"invalid length prefix size: 9"),
+	)
+
+	It("should limit length prefix by its size", func() {
+		_, err := LengthPrefix{Size: 1, MaxFrame: 1000}.AppendFrame(nil,
+			make([]byte, 256))
+		Expect(err).To(MatchError("frame length 256 exceeds 255"))
+	})
+
+	DescribeTable("resync", func(f Framing, encoded string,
+		expected []string, discards []string) {
+		b, err := ParseHex(encoded)
+		Expect(err).To(Succeed())
+		got, ds, err := readFrames(f, b)
+		Expect(err).To(Succeed())
+		hex := make([]string, len(got))
+		for i, p := range got {
+			hex[i] = FancyHex(p)
+		}
+		Expect(hex).To(Equal(expected))
+		Expect(ds).To(Equal(discards))
+	},
+		Entry("SLIP invalid escape", SLIP{}, "01 c0 db 02 c0 03 c0 04",
+			[]string{".1", ".3"},
+			[]string{"invalid escape db .2 c.", "truncated frame .4"}),
+		Entry("SLIP too long", SLIP{MaxFrame: 2}, "01 02 03 04 05 c0 06 c0",
+			[]string{".6"}, []string{"frame too long .1 .2 .3 .4 .5"}),
+		Entry("SLIP decoded too long", SLIP{MaxFrame: 2},
+			"01 db dc 03 c0 06 c0",
+			[]string{".6"}, []string{"frame too long .1 db dc .3 c."}),
+		Entry("COBS invalid code", COBS{}, "05 01 00 02 01 00",
+			[]string{".1"}, []string{"invalid COBS code .5 .1 .."}),
+		Entry("STXETX garbage", STXETX{}, "ff 02 01 03 fe 02 02 05 03",
+			[]string{".1", ".5"},
+			[]string{"garbage ff", "garbage fe", "truncated frame .2"}),
+		Entry("STXETX invalid escape", STXETX{}, "02 10 01 02 05 03 02",
+			[]string{".5"},
+			[]string{"invalid escape .2 1. .1", "truncated frame .2"}),
+		Entry("STXETX truncated", STXETX{}, "02 01 03 02 01",
+			[]string{".1"}, []string{"truncated frame .2 .1"}),
+		Entry("STXETX too long escaped", STXETX{MaxFrame: 1},
+			"02 01 05 03 02 03",
+			[]string{""}, []string{"frame too long .2 .1 .5", "garbage .3"}),
+	)
+
+	It("should fail invalid length prefix", func() {
+		_, _, err := readFrames(LengthPrefix{MaxFrame: 2}, []byte{0, 3, 1, 2, 3})
+		Expect(err).To(MatchError("frame length 3 exceeds 2"))
+		_, _, err = readFrames(LengthPrefix{}, []byte{0, 3, 1})
+		Expect(err).To(Equal(io.ErrUnexpectedEOF))
+	})
+
+	It("should work as bufio.SplitFunc", func() {
+		s := bufio.NewScanner(strings.NewReader("\x01\xc0\x02\xc0"))
+		s.Split(SLIP{}.Splitter(nil))
+		var got []string
+		for s.Scan() {
+			got = append(got, FancyHex(s.Bytes()))
+		}
+		Expect(s.Err()).To(Succeed())
+		Expect(got).To(Equal([]string{".1", ".2"}))
+	})
+
+	It("should read and write as io.Reader and io.Writer", func() {
+		var buf bytes.Buffer
+		w := NewFrameWriter(&buf, COBS{})
+		var logs []string
+		logf := func(format string, a ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, a...))
+		}
+		w.OnFrame = FrameLogger(logf, "tx")
+		n, err := w.Write([]byte{1, 2, 3})
+		Expect(n).To(Equal(3))
+		Expect(err).To(Succeed())
+		buf.Write([]byte{0xFF, 0xFF})
+
+		r := NewFrameReader(&buf, COBS{})
+		r.OnFrame = FrameLogger(logf, "rx")
+		r.OnDiscard = DiscardLogger(logf, "rx")
+		p := make([]byte, 2)
+		n, err = r.Read(p)
+		Expect(err).To(Succeed())
+		Expect(p[:n]).To(Equal([]byte{1, 2}))
+		n, err = r.Read(p)
+		Expect(err).To(Succeed())
+		Expect(p[:n]).To(Equal([]byte{3}))
+		_, err = r.Read(p)
+		Expect(err).To(Equal(io.EOF))
+
+		Expect(logs).To(Equal([]string{
+			"tx [.1 .2 .3]",
+			"rx [.1 .2 .3]",
+			"rx truncated frame [ff ff]",
+		}))
+	})
+
+	It("should skip empty frames", func() {
+		var buf bytes.Buffer
+		w := NewFrameWriter(&buf, COBS{})
+		for _, f := range [][]byte{{}, {1}, {}, {}, {2}, {}} {
+			Expect(w.WriteFrame(f)).To(Succeed())
+		}
+		b := buf.Bytes()
+
+		r := NewFrameReader(bytes.NewReader(b), COBS{})
+		Expect(r.ReadFrame()).To(BeEmpty())
+		Expect(r.ReadFrame()).To(Equal([]byte{1}))
+
+		r = NewFrameReader(bytes.NewReader(b), COBS{})
+		p := make([]byte, 4)
+		n, err := r.Read(p)
+		Expect(err).To(Succeed())
+		Expect(p[:n]).To(Equal([]byte{1}))
+		n, err = r.Read(p)
+		Expect(err).To(Succeed())
+		Expect(p[:n]).To(Equal([]byte{2}))
+		_, err = r.Read(p)
+		Expect(err).To(Equal(io.EOF))
+	})
+
+	It("should fail writing", func() {
+		w := NewFrameWriter(&failWriter{}, SLIP{})
+		Expect(w.WriteFrame([]byte{1})).NotTo(Succeed())
+		_, err := w.Write(make([]byte, FRAME_MAX_DEFAULT+1))
+		Expect(err).To(MatchError("frame length 4097 exceeds 4096"))
+	})
+})
@@ -3,6 +3,7 @@ module github.com/hanindo/util/v2
 go 1.16
 
 require (
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/onsi/ginkgo v1.16.4
 	github.com/onsi/gomega v1.12.0
 )
@@ -0,0 +1,275 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Maximum LCS table cells before HexDiff falls back to positional
+// comparison of the differing middle part.
+const HEX_DIFF_MAX_CELLS = 1 << 22
+
+const hex_diff_width = 16
+
+// hexDiffOp is a diff column, kind is one of ``='' equal, ``~'' changed,
+// ``-'' deleted from a, or ``+'' inserted in b.
+type hexDiffOp struct {
+	kind byte
+	a, b int
+}
+
+// HexDiff returns byte level diff of a and b in FancyHex layout. The bytes
+// are aligned by their longest common subsequence, so insertions and
+// deletions don't shift the rest. Each row shows 16 columns of a prefixed
+// by ``-'' and b prefixed by ``+'' with their offsets, followed by a marker
+// row of ``^^'' changed, ``--'' deleted and ``++'' inserted bytes. Runs of
+// equal rows are collapsed, and the last line is a summary, e.g.
+//     -0000  .1 .2 .3 .4 .5
+//     +0000  .1 ff .3    .5 .6
+//               ^^    --    ++
+//     1 changed, 1 deleted, 1 inserted; 5 vs 5 bytes
+func HexDiff(a, b []byte) string {
+	ops := hexDiffOps(a, b)
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	w := len(fmt.Sprintf("%x", n))
+	if w < 4 {
+		w = 4
+	}
+
+	var sb strings.Builder
+	var changed, deleted, inserted, equalRows int
+	flushEqual := func(end int) {
+		if equalRows > 1 {
+			fmt.Fprintf(&sb, " %s  %d equal bytes\n",
+				strings.Repeat(".", w), equalRows*hex_diff_width)
+		} else if equalRows == 1 {
+			hexDiffRow(&sb, ops[end-hex_diff_width:end], a, b, w)
+		}
+		equalRows = 0
+	}
+
+	for i := 0; i < len(ops); i += hex_diff_width {
+		e := i + hex_diff_width
+		if e > len(ops) {
+			e = len(ops)
+		}
+		row := ops[i:e]
+		equal := true
+		for _, op := range row {
+			switch op.kind {
+			case '~':
+				changed++
+			case '-':
+				deleted++
+			case '+':
+				inserted++
+			}
+			equal = equal && op.kind == '='
+		}
+		if equal && len(row) == hex_diff_width {
+			equalRows++
+			continue
+		}
+		flushEqual(i)
+		hexDiffRow(&sb, row, a, b, w)
+	}
+	flushEqual(len(ops) - len(ops)%hex_diff_width)
+
+	if changed+deleted+inserted == 0 {
+		fmt.Fprintf(&sb, "equal; %d bytes", len(a))
+	} else {
+		fmt.Fprintf(&sb, "%d changed, %d deleted, %d inserted; %d vs %d bytes",
+			changed, deleted, inserted, len(a), len(b))
+	}
+	return sb.String()
+}
+
+// hexDiffRow writes the a, b and marker lines of ops.
+// The offsets are formatted in w digits.
+func hexDiffRow(sb *strings.Builder, ops []hexDiffOp, a, b []byte, w int) {
+	var la, lb, lm strings.Builder
+	fmt.Fprintf(&la, "-%0*x ", w, ops[0].a)
+	fmt.Fprintf(&lb, "+%0*x ", w, ops[0].b)
+	lm.WriteString(strings.Repeat(" ", w+2))
+	for _, op := range ops {
+		ca, cb, m := "  ", "  ", "  "
+		if op.kind != '+' {
+			ca = FancyHex(a[op.a : op.a+1])
+		}
+		if op.kind != '-' {
+			cb = FancyHex(b[op.b : op.b+1])
+		}
+		switch op.kind {
+		case '~':
+			m = "^^"
+		case '-':
+			m = "--"
+		case '+':
+			m = "++"
+		}
+		la.WriteString(" " + ca)
+		lb.WriteString(" " + cb)
+		lm.WriteString(" " + m)
+	}
+	for _, l := range []*strings.Builder{&la, &lb, &lm} {
+		s := strings.TrimRight(l.String(), " ")
+		if s != "" {
+			sb.WriteString(s)
+			sb.WriteByte('\n')
+		}
+	}
+}
+
+// hexDiffOps returns the diff columns of a and b. The offsets of inserted
+// column in a, and deleted column in b, are the next byte offsets.
+func hexDiffOps(a, b []byte) []hexDiffOp {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre &&
+		a[len(a)-suf-1] == b[len(b)-suf-1] {
+		suf++
+	}
+
+	var ops []hexDiffOp
+	for i := 0; i < pre; i++ {
+		ops = append(ops, hexDiffOp{'=', i, i})
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	if (len(ma)+1)*(len(mb)+1) > HEX_DIFF_MAX_CELLS {
+		ops = append(ops, positionalOps(ma, mb, pre)...)
+	} else {
+		ops = append(ops, lcsOps(ma, mb, pre)...)
+	}
+	for i := suf; i > 0; i-- {
+		ops = append(ops, hexDiffOp{'=', len(a) - i, len(b) - i})
+	}
+	return ops
+}
+
+// positionalOps compares a and b byte by byte.
+func positionalOps(a, b []byte, base int) []hexDiffOp {
+	var ops []hexDiffOp
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			ops = append(ops, hexDiffOp{'+', base + len(a), base + i})
+		case i >= len(b):
+			ops = append(ops, hexDiffOp{'-', base + i, base + len(b)})
+		case a[i] == b[i]:
+			ops = append(ops, hexDiffOp{'=', base + i, base + i})
+		default:
+			ops = append(ops, hexDiffOp{'~', base + i, base + i})
+		}
+	}
+	return ops
+}
+
+// lcsOps aligns a and b by their longest common subsequence, adjacent
+// deletions and insertions are paired as changes.
+func lcsOps(a, b []byte, base int) []hexDiffOp {
+	n, m := len(a), len(b)
+	// t[i][j] is LCS length of a[i:] and b[j:]
+	t := make([][]int32, n+1)
+	for i := range t {
+		t[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				t[i][j] = t[i+1][j+1] + 1
+			} else if t[i+1][j] >= t[i][j+1] {
+				t[i][j] = t[i+1][j]
+			} else {
+				t[i][j] = t[i][j+1]
+			}
+		}
+	}
+
+	var ops []hexDiffOp
+	var dels, inss []hexDiffOp
+	flush := func() {
+		k := 0
+		for ; k < len(dels) && k < len(inss); k++ {
+			ops = append(ops, hexDiffOp{'~', dels[k].a, inss[k].b})
+		}
+		ops = append(ops, dels[k:]...)
+		ops = append(ops, inss[k:]...)
+		dels, inss = dels[:0], inss[:0]
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			flush()
+			ops = append(ops, hexDiffOp{'=', base + i, base + j})
+			i++
+			j++
+		case j >= m || (i < n && t[i+1][j] >= t[i][j+1]):
+			dels = append(dels, hexDiffOp{'-', base + i, base + j})
+			i++
+		default:
+			inss = append(inss, hexDiffOp{'+', base + i, base + j})
+			j++
+		}
+	}
+	flush()
+	return ops
+}
+
+//============================================================================
+
+// EqualBytes returns Gomega matcher that succeeds if actual []byte or
+// string is equal to expected, the failure message shows the HexDiff of
+// expected and actual.
+//
+//     Expect(frame).To(EqualBytes([]byte{0x02, 0x10, 0x03}))
+func EqualBytes(expected []byte) *BytesMatcher {
+	return &BytesMatcher{Expected: expected}
+}
+
+// BytesMatcher implements Gomega types.GomegaMatcher interface,
+// see EqualBytes.
+type BytesMatcher struct {
+	Expected []byte
+}
+
+func toBytes(actual interface{}) ([]byte, error) {
+	switch v := actual.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("EqualBytes matcher expects []byte or string, "+
+		"got %T", actual)
+}
+
+// Match reports whether actual is equal to the expected bytes.
+func (m *BytesMatcher) Match(actual interface{}) (bool, error) {
+	b, err := toBytes(actual)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(b, m.Expected), nil
+}
+
+// FailureMessage returns the diff of expected and actual.
+func (m *BytesMatcher) FailureMessage(actual interface{}) string {
+	b, _ := toBytes(actual)
+	return "Expected bytes to equal (- expected, + actual):\n" +
+		Indent(HexDiff(m.Expected, b), "    ")
+}
+
+// NegatedFailureMessage returns the message for unexpectedly equal bytes.
+func (m *BytesMatcher) NegatedFailureMessage(actual interface{}) string {
+	b, _ := toBytes(actual)
+	return "Expected bytes not to equal:\n" +
+		HexWrap(b, "    [", "]", "      ", 76, 80)
+}
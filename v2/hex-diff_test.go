@@ -0,0 +1,90 @@
+package util_test
+
+import (
+	"bytes"
+	. "github.com/hanindo/util/v2"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HexDiff", func() {
+	seq := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(i)
+		}
+		return b
+	}
+
+	DescribeTable("diff",
+		func(a, b []byte, x string) {
+			Expect(HexDiff(a, b)).To(Equal(strings.TrimPrefix(x, "\n")))
+		},
+		Entry("empty", []byte{}, nil, "equal; 0 bytes"),
+		Entry("doc", []byte{1, 2, 3, 4, 5}, []byte{1, 0xFF, 3, 5, 6}, `
+-0000  .1 .2 .3 .4 .5
++0000  .1 ff .3    .5 .6
+          ^^    --    ++
+1 changed, 1 deleted, 1 inserted; 5 vs 5 bytes`),
+		Entry("insert", []byte{1, 2, 3}, []byte{1, 2, 0xA, 0xB, 3}, `
+-0000  .1 .2       .3
++0000  .1 .2 .a .b .3
+             ++ ++
+0 changed, 0 deleted, 2 inserted; 3 vs 5 bytes`),
+		Entry("delete tail", []byte{1, 2, 3}, []byte{1}, `
+-0000  .1 .2 .3
++0000  .1
+          -- --
+0 changed, 2 deleted, 0 inserted; 3 vs 1 bytes`),
+		Entry("equal rows", append(seq(40), 0xAA), append(seq(40), 0xBB), `
+ ....  32 equal bytes
+-0020  2. 21 22 23 24 25 26 27 aa
++0020  2. 21 22 23 24 25 26 27 bb
+                               ^^
+1 changed, 0 deleted, 0 inserted; 41 vs 41 bytes`),
+		Entry("single equal row", append([]byte{0xAA}, seq(31)...),
+			append([]byte{0xBB}, seq(31)...), `
+-0000  aa .. .1 .2 .3 .4 .5 .6 .7 .8 .9 .a .b .c .d .e
++0000  bb .. .1 .2 .3 .4 .5 .6 .7 .8 .9 .a .b .c .d .e
+       ^^
+-0010  .f 1. 11 12 13 14 15 16 17 18 19 1a 1b 1c 1d 1e
++0010  .f 1. 11 12 13 14 15 16 17 18 19 1a 1b 1c 1d 1e
+1 changed, 0 deleted, 0 inserted; 32 vs 32 bytes`),
+		Entry("shifted offsets", append([]byte{0xAA}, seq(20)...), seq(20), `
+-0000  aa .. .1 .2 .3 .4 .5 .6 .7 .8 .9 .a .b .c .d .e
++0000     .. .1 .2 .3 .4 .5 .6 .7 .8 .9 .a .b .c .d .e
+       --
+-0010  .f 1. 11 12 13
++000f  .f 1. 11 12 13
+0 changed, 1 deleted, 0 inserted; 21 vs 20 bytes`),
+	)
+
+	It("should fall back to positional diff", func() {
+		a := bytes.Repeat([]byte{1, 2}, 2100)
+		b := bytes.Repeat([]byte{2, 1}, 2100)
+		Expect(HexDiff(a, b)).To(HaveSuffix(
+			"4200 changed, 0 deleted, 0 inserted; 4200 vs 4200 bytes"))
+	})
+
+	It("should match bytes", func() {
+		Expect([]byte{1, 2, 3}).To(EqualBytes([]byte{1, 2, 3}))
+		Expect("abc").To(EqualBytes([]byte("abc")))
+		Expect([]byte{1, 2}).NotTo(EqualBytes([]byte{1, 2, 3}))
+
+		m := EqualBytes([]byte{1, 2, 3})
+		Expect(m.FailureMessage([]byte{1, 3})).To(Equal(`Expected bytes to equal (- expected, + actual):
+    -0000  .1 .2 .3
+    +0000  .1    .3
+              --
+    0 changed, 1 deleted, 0 inserted; 3 vs 2 bytes`))
+		Expect(m.NegatedFailureMessage([]byte{1, 2, 3})).To(Equal(
+			"Expected bytes not to equal:\n    [.1 .2 .3]"))
+
+		_, err := m.Match(123)
+		Expect(err).To(MatchError(
+			"EqualBytes matcher expects []byte or string, got int"))
+	})
+})
@@ -0,0 +1,215 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HexError is the error returned by ParseHex for malformed input, it tells
+// the exact position of the problem in the input text.
+type HexError struct {
+	// Byte offset in the input text
+	Offset int
+	// Line and column number, both starting from 1. The column is counted
+	// in bytes.
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error returns the error message with its position, e.g.
+//     invalid hex character 'g' at line 2 column 7
+func (e *HexError) Error() string {
+	return fmt.Sprintf("%s at line %d column %d", e.Msg, e.Line, e.Column)
+}
+
+// hexErrorf returns *HexError at off offset of s text.
+func hexErrorf(s string, off int, format string, a ...interface{}) error {
+	line := strings.Count(s[:off], "\n") + 1
+	col := off - strings.LastIndexByte(s[:off], '\n')
+	return &HexError{
+		Offset: off,
+		Line:   line,
+		Column: col,
+		Msg:    fmt.Sprintf(format, a...),
+	}
+}
+
+// ParseHex parses hex text back into bytes. It is the inverse of FancyHex,
+// and also accepts:
+//     plain hex       0a0b 0c, any whitespace or comma separated
+//     0x prefixed     0x0a, 0x0b0c
+//     FancyHex        .a .b .c
+//     hexdump -C      00000000  0a 0b 0c  |...|
+// Each token must have even number of hex digits, ``.'' counts as ``0''.
+// For HexDumper output, the squeezed ``*'' lines are expanded and the
+// offsets are checked. The error is *HexError.
+func ParseHex(s string) ([]byte, error) {
+	return parseHex(s, 0, len(s))
+}
+
+// ParseHexWrap parses HexWrap output back into bytes. The text before prefix
+// and after postfix are ignored, so it can parse a whole log line or the
+// multi-line wrapped layout. The error is *HexError.
+func ParseHexWrap(s, prefix, postfix string) ([]byte, error) {
+	start, end := 0, len(s)
+	if prefix != "" {
+		i := strings.Index(s, prefix)
+		if i < 0 {
+			return nil, hexErrorf(s, 0, "missing prefix %q", prefix)
+		}
+		start = i + len(prefix)
+	}
+	if postfix != "" {
+		i := strings.LastIndex(s[start:], postfix)
+		if i < 0 {
+			return nil, hexErrorf(s, len(s), "missing postfix %q", postfix)
+		}
+		end = start + i
+	}
+	return parseHex(s, start, end)
+}
+
+var hexDumpLineRE = regexp.MustCompile(
+	`^\s*([0-9a-fA-F]{8,})  ([^|]*)\|.*\|\s*$`)
+var hexDumpEndRE = regexp.MustCompile(`^\s*([0-9a-fA-F]{8,})\s*$`)
+
+// hexDumpState tracks the offsets of hexdump lines.
+type hexDumpState struct {
+	started bool
+	next    int64
+	prev    []byte
+	star    bool
+	ended   bool
+}
+
+// seek fills the squeezed lines up to off offset.
+func (h *hexDumpState) seek(s string, pos int, off int64, b []byte) ([]byte,
+	error) {
+	if !h.started {
+		h.started, h.next = true, off
+		return b, nil
+	}
+	if h.star && off > h.next && len(h.prev) > 0 &&
+		(off-h.next)%int64(len(h.prev)) == 0 {
+		for ; h.next < off; h.next += int64(len(h.prev)) {
+			b = append(b, h.prev...)
+		}
+	}
+	if off != h.next {
+		return nil, hexErrorf(s, pos, "unexpected offset %x, expecting %x",
+			off, h.next)
+	}
+	h.star = false
+	return b, nil
+}
+
+func parseHex(s string, start, end int) ([]byte, error) {
+	b := []byte{}
+	var hd hexDumpState
+	for start <= end {
+		lend := strings.IndexByte(s[start:end], '\n')
+		if lend < 0 {
+			lend = end
+		} else {
+			lend += start
+		}
+		line := s[start:lend]
+
+		var err error
+		if m := hexDumpLineRE.FindStringSubmatchIndex(line); m != nil &&
+			!hd.ended {
+			off, _ := strconv.ParseInt(line[m[2]:m[3]], 16, 64)
+			if b, err = hd.seek(s, start+m[2], off, b); err != nil {
+				return nil, err
+			}
+			n := len(b)
+			b, err = parseHexTokens(s, start+m[4], start+m[5], b)
+			if err != nil {
+				return nil, err
+			}
+			hd.prev = append(hd.prev[:0], b[n:]...)
+			hd.next += int64(len(b) - n)
+		} else if hd.started && strings.TrimSpace(line) == "*" && !hd.ended {
+			hd.star = true
+		} else if m := hexDumpEndRE.FindStringSubmatchIndex(line); m != nil &&
+			hd.started && !hd.ended {
+			off, _ := strconv.ParseInt(line[m[2]:m[3]], 16, 64)
+			if b, err = hd.seek(s, start+m[2], off, b); err != nil {
+				return nil, err
+			}
+			hd.ended = true
+		} else if hd.started && strings.TrimSpace(line) != "" {
+			i := start + len(line) - len(strings.TrimLeft(line, " \t\r"))
+			return nil, hexErrorf(s, i, "invalid hexdump line")
+		} else if b, err = parseHexTokens(s, start, lend, b); err != nil {
+			return nil, err
+		}
+		start = lend + 1
+	}
+	if hd.star {
+		return nil, hexErrorf(s, end, "missing end offset after ``*''")
+	}
+	return b, nil
+}
+
+// parseHexTokens parses s[start:end] hex tokens and appends them to b.
+func parseHexTokens(s string, start, end int, b []byte) ([]byte, error) {
+	isSep := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ','
+	}
+	for i := start; i < end; {
+		for i < end && isSep(s[i]) {
+			i++
+		}
+		j := i
+		for j < end && !isSep(s[j]) {
+			j++
+		}
+		if i == j {
+			break
+		}
+
+		tok := i
+		if j-i >= 2 && s[i] == '0' && (s[i+1] == 'x' || s[i+1] == 'X') {
+			i += 2
+			if i == j {
+				return nil, hexErrorf(s, tok, "missing hex digits")
+			}
+		}
+		if (j-i)%2 != 0 {
+			return nil, hexErrorf(s, tok, "odd number of hex digits: %q",
+				s[tok:j])
+		}
+		for ; i < j; i += 2 {
+			hi, ok := unhex(s[i])
+			if !ok {
+				return nil, hexErrorf(s, i, "invalid hex character %q", s[i])
+			}
+			lo, ok := unhex(s[i+1])
+			if !ok {
+				return nil, hexErrorf(s, i+1, "invalid hex character %q",
+					s[i+1])
+			}
+			b = append(b, hi<<4|lo)
+		}
+	}
+	return b, nil
+}
+
+// unhex returns the value of hex digit c, ``.'' is zero as in FancyHex.
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c == '.':
+		return 0, true
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
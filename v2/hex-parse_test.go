@@ -0,0 +1,125 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseHex", func() {
+	DescribeTable("valid",
+		func(s string, x []byte) {
+			Expect(ParseHex(s)).To(Equal(x))
+		},
+		Entry("empty", "", []byte{}),
+		Entry("blank", " \n\t ", []byte{}),
+		Entry("fancy", ".. .1 a. 23", []byte{0x00, 0x01, 0xA0, 0x23}),
+		Entry("plain", "0a0B\t0c\r\n0d", []byte{0x0A, 0x0B, 0x0C, 0x0D}),
+		Entry("prefix", "0x0a, 0X0b0c,0xFF", []byte{0x0A, 0x0B, 0x0C, 0xFF}),
+		Entry("hexdump", ""+
+			"00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 0a 00 01  |Hello, world!...|\n"+
+			"00000010  02                                                |.|\n",
+			[]byte("Hello, world!\n\x00\x01\x02")),
+		Entry("hexdump offset", ""+
+			"  00000100  02 10 00 05  50 49 4E 47  |....PING|\n"+
+			"  00000108  5A 03                     |Z.|",
+			[]byte{0x02, 0x10, 0x00, 0x05, 'P', 'I', 'N', 'G', 0x5A, 0x03}),
+		Entry("hexdump squeeze", ""+
+			"00000000  41 41  |AA|\n"+
+			"*\n"+
+			"00000006  42     |B|\n"+
+			"00000007\n",
+			[]byte("AAAAAAB")),
+		Entry("hexdump squeeze end", ""+
+			"00000000  41 41  |AA|\n"+
+			"00000002  42 42  |BB|\n"+
+			"*\n"+
+			"00000008\n",
+			[]byte("AABBBBBB")),
+	)
+
+	DescribeTable("invalid",
+		func(s, x string, off, line, col int) {
+			_, err := ParseHex(s)
+			Expect(err).To(MatchError(x))
+			Expect(err).To(BeAssignableToTypeOf(&HexError{}))
+			he := err.(*HexError)
+			Expect(he.Offset).To(Equal(off))
+			Expect(he.Line).To(Equal(line))
+			Expect(he.Column).To(Equal(col))
+		},
+		Entry("character", ".. .1\n  a. 2g",
+			"invalid hex character 'g' at line 2 column 7", 12, 2, 7),
+		Entry("high nibble", "zz",
+			"invalid hex character 'z' at line 1 column 1", 0, 1, 1),
+		Entry("odd", "01 0x123",
+			`odd number of hex digits: "0x123" at line 1 column 4`, 3, 1, 4),
+		Entry("prefix only", "01 0x",
+			"missing hex digits at line 1 column 4", 3, 1, 4),
+		Entry("hexdump offset", ""+
+			"00000000  41 41  |AA|\n"+
+			"00000004  42 42  |BB|\n",
+			"unexpected offset 4, expecting 2 at line 2 column 1", 22, 2, 1),
+		Entry("hexdump squeeze", ""+
+			"00000000  41 41  |AA|\n"+
+			"*\n"+
+			"00000005  42     |B|\n",
+			"unexpected offset 5, expecting 2 at line 3 column 1", 24, 3, 1),
+		Entry("hexdump hex", ""+
+			"00000000  41 4x  |AA|\n",
+			"invalid hex character 'x' at line 1 column 15", 14, 1, 15),
+		Entry("hexdump mixed", ""+
+			"00000000  41 41  |AA|\n"+
+			"  42 42\n",
+			"invalid hexdump line at line 2 column 3", 24, 2, 3),
+		Entry("hexdump no end", ""+
+			"00000000  41 41  |AA|\n"+
+			"*\n",
+			"missing end offset after ``*'' at line 3 column 1", 24, 3, 1),
+	)
+
+	It("should parse FancyHex and HexDump output", func() {
+		r := rand.New(rand.NewSource(1))
+		for n := 0; n < 100; n++ {
+			b := make([]byte, r.Intn(100))
+			r.Read(b)
+			if n%2 == 0 {
+				for i := 10; i < len(b)/2; i++ {
+					b[i] = 0
+				}
+			}
+			Expect(ParseHex(FancyHex(b))).To(Equal(b))
+			Expect(ParseHex(HexDump(b))).To(Equal(b))
+			Expect(ParseHex(HexDumper{Width: 4, Offset: 7, Upper: true,
+				Squeeze: true}.Dump(b))).To(Equal(b))
+		}
+	})
+
+	DescribeTable("ParseHexWrap",
+		func(b []byte) {
+			s := "2021-12-21 13:45:56.789 I " +
+				HexWrap(b, "raw rx: [", "]", "  ", 54, 80) + " done"
+			Expect(ParseHexWrap(s, "raw rx: [", "]")).To(Equal(b))
+		},
+		Entry("short", []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}),
+		Entry("long", []byte{
+			15, 16, 17, 18, 19, 20, 21, 22, 23, 24,
+			25, 26, 27, 28, 29, 30, 31, 32, 33, 34,
+			35, 36, 37, 38, 39, 40, 41, 42, 43, 44,
+		}),
+	)
+
+	It("should report missing prefix or postfix", func() {
+		_, err := ParseHexWrap("raw tx: [.1]", "raw rx: [", "]")
+		Expect(err).To(MatchError(`missing prefix "raw rx: [" ` +
+			"at line 1 column 1"))
+		_, err = ParseHexWrap("raw rx: [.1\n", "raw rx: [", "]")
+		Expect(err).To(MatchError(`missing postfix "]" at line 2 column 1`))
+		_, err = ParseHexWrap("raw rx: [\n  .1 .x\n]", "raw rx: [", "]")
+		Expect(err).To(MatchError(
+			"invalid hex character 'x' at line 2 column 7"))
+	})
+})
@@ -0,0 +1,243 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Default hexdump highlight markers, ANSI reverse video on and off.
+const (
+	HEX_HIGHLIGHT_BEFORE = "\x1b[7m"
+	HEX_HIGHLIGHT_AFTER  = "\x1b[0m"
+)
+
+// HexHighlight marks bytes from Start up to but excluding End offset in
+// HexDumper output. The markers should be zero width on the output device,
+// like ANSI escape codes, to keep the columns aligned. Empty markers are
+// replaced by HEX_HIGHLIGHT_BEFORE and HEX_HIGHLIGHT_AFTER.
+type HexHighlight struct {
+	Start  int64
+	End    int64
+	Before string
+	After  string
+}
+
+// HexDumper formats bytes like ``hexdump -C'' output:
+//     00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 0a 00 01  |Hello, world!...|
+//     00000010  02                                                |.|
+// The ASCII gutter shows printable characters by IsAscii rule, other bytes
+// are shown as ``.''. The zero value is ready to use.
+type HexDumper struct {
+	// Bytes per line, defaults to 16
+	Width int
+	// Bytes per group separated by extra space, defaults to 8.
+	// Negative value disables grouping.
+	Group int
+	// Offset of the first byte
+	Offset int64
+	// Use upper case hex digits
+	Upper bool
+	// Replace repeated identical lines with a single ``*'' line, the dump is
+	// then terminated by the end offset line so the length is not lost.
+	Squeeze bool
+	// Highlighted byte ranges, the first matching range is used.
+	Highlights []HexHighlight
+}
+
+// HexDump returns ``hexdump -C'' like output of b without trailing newline.
+// See HexDumper for more options.
+func HexDump(b []byte) string {
+	return HexDumper{}.Dump(b)
+}
+
+// Dump returns the hexdump of b without trailing newline.
+func (h HexDumper) Dump(b []byte) string {
+	var sb strings.Builder
+	w := h.Writer(&sb)
+	w.Write(b)
+	w.Close()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Writer returns io.WriteCloser that writes the hexdump of all written bytes
+// to w, line by line as soon as they are complete. The last partial line is
+// written on Close, which doesn't close w.
+func (h HexDumper) Writer(w io.Writer) io.WriteCloser {
+	if h.Width <= 0 {
+		h.Width = 16
+	}
+	if h.Group == 0 {
+		h.Group = 8
+	}
+	return &hexDumpWriter{h: h, w: w, off: h.Offset}
+}
+
+// highlight returns the index of highlight containing off, or -1 if none.
+func (h HexDumper) highlight(off int64) int {
+	for i, hl := range h.Highlights {
+		if off >= hl.Start && off < hl.End {
+			return i
+		}
+	}
+	return -1
+}
+
+// highlighted reports whether any byte in [start, end) is highlighted.
+func (h HexDumper) highlighted(start, end int64) bool {
+	for _, hl := range h.Highlights {
+		if hl.Start < end && hl.End > start {
+			return true
+		}
+	}
+	return false
+}
+
+func (h HexDumper) markers(i int) (string, string) {
+	before, after := h.Highlights[i].Before, h.Highlights[i].After
+	if before == "" {
+		before = HEX_HIGHLIGHT_BEFORE
+	}
+	if after == "" {
+		after = HEX_HIGHLIGHT_AFTER
+	}
+	return before, after
+}
+
+// column writes cell of every byte in b, preceded by sep except the first
+// one. The highlighted runs are surrounded by their markers.
+func (h HexDumper) column(buf *bytes.Buffer, off int64, b []byte,
+	sep string, cell func(c byte)) {
+	cur := -1
+	for i, c := range b {
+		if hl := h.highlight(off + int64(i)); hl != cur {
+			if cur >= 0 {
+				_, after := h.markers(cur)
+				buf.WriteString(after)
+			}
+			if i > 0 {
+				buf.WriteString(h.sep(i, sep))
+			}
+			if hl >= 0 {
+				before, _ := h.markers(hl)
+				buf.WriteString(before)
+			}
+			cur = hl
+		} else if i > 0 {
+			buf.WriteString(h.sep(i, sep))
+		}
+		cell(c)
+	}
+	if cur >= 0 {
+		_, after := h.markers(cur)
+		buf.WriteString(after)
+	}
+}
+
+// sep returns separator before i-th byte of a line, with extra space at
+// group boundary.
+func (h HexDumper) sep(i int, sep string) string {
+	if sep != "" && h.Group > 0 && i%h.Group == 0 {
+		return sep + " "
+	}
+	return sep
+}
+
+// line writes a dump line of b at off offset.
+func (h HexDumper) line(buf *bytes.Buffer, off int64, b []byte) {
+	digits := "0123456789abcdef"
+	offFmt := "%08x  "
+	if h.Upper {
+		digits = "0123456789ABCDEF"
+		offFmt = "%08X  "
+	}
+	fmt.Fprintf(buf, offFmt, off)
+
+	h.column(buf, off, b, " ", func(c byte) {
+		buf.WriteByte(digits[c>>4])
+		buf.WriteByte(digits[c&0x0F])
+	})
+	for i := len(b); i < h.Width; i++ {
+		if i > 0 {
+			buf.WriteString(h.sep(i, " "))
+		}
+		buf.WriteString("  ")
+	}
+
+	buf.WriteString("  |")
+	h.column(buf, off, b, "", func(c byte) {
+		if IsAscii([]byte{c}) {
+			buf.WriteByte(c)
+		} else {
+			buf.WriteByte('.')
+		}
+	})
+	buf.WriteString("|\n")
+}
+
+type hexDumpWriter struct {
+	h    HexDumper
+	w    io.Writer
+	buf  []byte
+	line bytes.Buffer
+	off  int64
+	prev []byte
+	star bool
+}
+
+func (d *hexDumpWriter) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+	i := 0
+	for ; len(d.buf)-i >= d.h.Width; i += d.h.Width {
+		if err := d.flush(d.buf[i : i+d.h.Width]); err != nil {
+			d.buf = d.buf[:copy(d.buf, d.buf[i+d.h.Width:])]
+			return len(p), err
+		}
+	}
+	d.buf = d.buf[:copy(d.buf, d.buf[i:])]
+	return len(p), nil
+}
+
+// flush writes b as a dump line, or the squeeze line if it is repeated.
+func (d *hexDumpWriter) flush(b []byte) error {
+	off := d.off
+	d.off += int64(len(b))
+	if d.h.Squeeze && d.prev != nil && bytes.Equal(b, d.prev) &&
+		!d.h.highlighted(off, d.off) {
+		if d.star {
+			return nil
+		}
+		d.star = true
+		_, err := io.WriteString(d.w, "*\n")
+		return err
+	}
+
+	d.star = false
+	d.prev = append(d.prev[:0], b...)
+	d.line.Reset()
+	d.h.line(&d.line, off, b)
+	_, err := d.w.Write(d.line.Bytes())
+	return err
+}
+
+// Close writes the last partial line, and the end offset line if the
+// output is squeezed and not empty.
+func (d *hexDumpWriter) Close() error {
+	if len(d.buf) > 0 {
+		b := d.buf
+		d.buf = nil
+		if err := d.flush(b); err != nil {
+			return err
+		}
+	}
+	if d.h.Squeeze && d.off > d.h.Offset {
+		f := "%08x\n"
+		if d.h.Upper {
+			f = "%08X\n"
+		}
+		_, err := fmt.Fprintf(d.w, f, d.off)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package util_test
+
+import (
+	"bytes"
+	"errors"
+	. "github.com/hanindo/util/v2"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type failWriter struct {
+	n int
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n--; w.n < 0 {
+		return 0, errors.New("write failed")
+	}
+	return len(p), nil
+}
+
+var _ = Describe("HexDumper", func() {
+	seq := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = byte(i + 0x30)
+		}
+		return b
+	}
+
+	DescribeTable("Dump",
+		func(h HexDumper, b []byte, x string) {
+			Expect(h.Dump(b)).To(Equal(strings.Join(strings.Split(x, "\n")[1:],
+				"\n")))
+		},
+		Entry("empty", HexDumper{}, []byte{}, "\n"),
+		Entry("full line", HexDumper{}, seq(16), `
+00000000  30 31 32 33 34 35 36 37  38 39 3a 3b 3c 3d 3e 3f  |0123456789:;<=>?|`),
+		Entry("partial group", HexDumper{}, seq(10), `
+00000000  30 31 32 33 34 35 36 37  38 39                    |0123456789|`),
+		Entry("width", HexDumper{Width: 6, Group: 4}, seq(8), `
+00000000  30 31 32 33  34 35  |012345|
+00000006  36 37               |67|`),
+		Entry("no group", HexDumper{Width: 4, Group: -1}, seq(5), `
+00000000  30 31 32 33  |0123|
+00000004  34           |4|`),
+		Entry("non printable", HexDumper{Width: 4}, []byte{0x1F, 0x20, 0x7E,
+			0x7F}, `
+00000000  1f 20 7e 7f  |. ~.|`),
+		Entry("offset", HexDumper{Offset: 0xFFFFFFFE, Width: 2}, seq(3), `
+fffffffe  30 31  |01|
+100000000  32     |2|`),
+		Entry("squeeze", HexDumper{Width: 4, Squeeze: true},
+			[]byte("AAAABBBBBBBBBBBBBBBBAAAAAAAAAA"), `
+00000000  41 41 41 41  |AAAA|
+00000004  42 42 42 42  |BBBB|
+*
+00000014  41 41 41 41  |AAAA|
+*
+0000001c  41 41        |AA|
+0000001e`),
+		Entry("squeeze highlight", HexDumper{Width: 2, Squeeze: true,
+			Highlights: []HexHighlight{{Start: 4, End: 5}}}, []byte("AAAAAAAA"), `
+00000000  41 41  |AA|
+*
+00000004  `+"\x1b[7m41\x1b[0m"+` 41  |`+"\x1b[7mA\x1b[0m"+`A|
+*
+00000008`),
+		Entry("highlight", HexDumper{Width: 4, Highlights: []HexHighlight{
+			{Start: 1, End: 3, Before: "<", After: ">"},
+			{Start: 2, End: 6, Before: "{", After: "}"},
+		}}, seq(6), `
+00000000  30 <31 32> {33}  |0<12>{3}|
+00000004  {34 35}        |{45}|`),
+	)
+
+	It("should stream lines", func() {
+		var buf bytes.Buffer
+		w := HexDumper{Width: 4}.Writer(&buf)
+		Expect(w.Write([]byte("abc"))).To(Equal(3))
+		Expect(buf.String()).To(BeEmpty())
+		Expect(w.Write([]byte("defgh"))).To(Equal(5))
+		Expect(buf.String()).To(Equal("" +
+			"00000000  61 62 63 64  |abcd|\n" +
+			"00000004  65 66 67 68  |efgh|\n"))
+		Expect(w.Write([]byte("i"))).To(Equal(1))
+		Expect(w.Close()).To(Succeed())
+		Expect(buf.String()).To(HaveSuffix("00000008  69           |i|\n"))
+	})
+
+	It("should return write error", func() {
+		w := HexDumper{Width: 4}.Writer(&failWriter{1})
+		_, err := w.Write(seq(6))
+		Expect(err).To(Succeed())
+		Expect(w.Close()).To(MatchError("write failed"))
+
+		w = HexDumper{Width: 4}.Writer(&failWriter{0})
+		_, err = w.Write(seq(6))
+		Expect(err).To(MatchError("write failed"))
+	})
+})
@@ -0,0 +1,39 @@
+package util
+
+import (
+	"bytes"
+)
+
+// JsonForm selects how the JSON wrapper types, like DateJson, VersionJson
+// and DecimalJson, encode their value. The types themselves are always encoded
+// as JSON_STRING.
+type JsonForm int
+
+// Available JSON forms.
+const (
+	// JSON_STRING encodes value as its String() output, e.g.
+	//     "2021-02-01 +07:00" or "1.2.3"
+	JSON_STRING JsonForm = iota
+	// JSON_OBJECT encodes value as an object of its components, e.g.
+	//     {"date":"2021-02-01","tz":"+07:00"} or
+	//     {"major":1,"minor":2,"patch":3}
+	JSON_OBJECT
+	// JSON_EPOCH encodes value as a number of seconds since Unix epoch,
+	// a date is encoded as its UTC midnight so the time zone is lost.
+	// Types that have no epoch representation use JSON_STRING instead.
+	JSON_EPOCH
+	// JSON_NUMBER encodes value as a number, e.g. 123.450 of Decimal.
+	// Types that have no number representation use JSON_STRING instead.
+	JSON_NUMBER
+)
+
+var jsonNull = []byte("null")
+
+// jsonKind returns the first significant byte of JSON value b.
+func jsonKind(b []byte) byte {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
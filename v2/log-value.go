@@ -0,0 +1,305 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Default maximum length of TruncateEnc.
+const TRUNCATE_DEFAULT = 256
+
+// Mask of RedactEnc hidden characters.
+const REDACT_MASK = "****"
+
+// The lazy formatted values below, like JsonEnc and HexWrapper, defer the
+// formatting until String() is called, so they are cheap to pass to a
+// disabled log level. They also implement fmt.Formatter, which accepts
+// ``%v'', ``%s'' and ``%q'' verbs with their flags, width and precision,
+// and slog.LogValuer on Go 1.21 or newer.
+
+// encError returns the error string of lazy formatted value.
+func encError(err error) string {
+	return "!(" + err.Error() + ")"
+}
+
+// formatString implements fmt.Formatter of v lazy value by formatting its s
+// string.
+func formatString(f fmt.State, verb rune, v interface{}, s string) {
+	switch verb {
+	case 'v':
+		verb = 's'
+	case 's', 'q':
+	default:
+		fmt.Fprintf(f, "%%!%c(%T)", verb, v)
+		return
+	}
+	format := "%"
+	for _, c := range "-+# 0" {
+		if f.Flag(int(c)) {
+			format += string(c)
+		}
+	}
+	if w, ok := f.Width(); ok {
+		format += strconv.Itoa(w)
+	}
+	if p, ok := f.Precision(); ok {
+		format += "." + strconv.Itoa(p)
+	}
+	fmt.Fprintf(f, format+string(verb), s)
+}
+
+// Format implements fmt.Formatter.
+func (e JsonEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+// Format implements fmt.Formatter.
+func (h hexWrapper) Format(f fmt.State, verb rune) {
+	formatString(f, verb, h, h.String())
+}
+
+//============================================================================
+
+// JsonIndentEnc is JsonEnc with indented output.
+type JsonIndentEnc struct {
+	V interface{}
+	// Indentation of each level, defaults to two spaces
+	Indent string
+}
+
+// String returns indented JSON of V field, or the error like JsonEnc.
+func (e JsonIndentEnc) String() string {
+	ind := e.Indent
+	if ind == "" {
+		ind = "  "
+	}
+	b, err := json.MarshalIndent(e.V, "", ind)
+	if err != nil {
+		return encError(err)
+	}
+	return string(b)
+}
+
+// Format implements fmt.Formatter.
+func (e JsonIndentEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+//============================================================================
+
+// KeyValueEnc formats V as YAML like key value lines, following its JSON
+// encoding and field order, e.g.
+//     name: abc
+//     tags:
+//       - a
+//       - b
+//     point:
+//       x: 1
+//       y: 2
+type KeyValueEnc struct {
+	V interface{}
+}
+
+// kvNode is a decoded JSON value, kind is ``{'' object, ``['' array, or
+// ``s'' scalar.
+type kvNode struct {
+	kind   byte
+	scalar string
+	keys   []string
+	vals   []*kvNode
+}
+
+func parseKeyValue(d *json.Decoder) (*kvNode, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := t.(type) {
+	case json.Delim:
+		n := &kvNode{kind: byte(t)}
+		for d.More() {
+			if t == '{' {
+				k, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, kvQuote(k.(string)))
+			}
+			v, err := parseKeyValue(d)
+			if err != nil {
+				return nil, err
+			}
+			n.vals = append(n.vals, v)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &kvNode{kind: 's', scalar: kvQuote(t)}, nil
+	case nil:
+		return &kvNode{kind: 's', scalar: "null"}, nil
+	}
+	return &kvNode{kind: 's', scalar: fmt.Sprint(t)}, nil
+}
+
+// kvQuote quotes s if it would be ambiguous unquoted.
+func kvQuote(s string) string {
+	_, err := strconv.ParseFloat(s, 64)
+	if s == "" || err == nil || strings.TrimSpace(s) != s ||
+		strings.ContainsAny(s, "\":#\n\t") || strings.HasPrefix(s, "-") ||
+		s == "true" || s == "false" || s == "null" {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func (n *kvNode) lines() []string {
+	if n.kind == 's' {
+		return []string{n.scalar}
+	}
+	if len(n.vals) == 0 && n.kind == '{' {
+		return []string{"{}"}
+	} else if len(n.vals) == 0 {
+		return []string{"[]"}
+	}
+	var ls []string
+	for i, v := range n.vals {
+		vl := v.lines()
+		switch {
+		case n.kind == '[':
+			ls = append(ls, "- "+vl[0])
+			vl = vl[1:]
+		case len(v.vals) == 0:
+			ls = append(ls, n.keys[i]+": "+vl[0])
+			vl = vl[1:]
+		default:
+			ls = append(ls, n.keys[i]+":")
+		}
+		for _, l := range vl {
+			ls = append(ls, "  "+l)
+		}
+	}
+	return ls
+}
+
+// String returns the key value lines of V field, or the error like JsonEnc.
+func (e KeyValueEnc) String() string {
+	b, err := json.Marshal(e.V)
+	if err != nil {
+		return encError(err)
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	n, err := parseKeyValue(d)
+	if err != nil {
+		return encError(err)
+	}
+	return strings.Join(n.lines(), "\n")
+}
+
+// Format implements fmt.Formatter.
+func (e KeyValueEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+//============================================================================
+
+// BitStringEnc formats B by BitString.
+type BitStringEnc struct {
+	B []byte
+}
+
+// String returns BitString of B field.
+func (e BitStringEnc) String() string {
+	return BitString(e.B)
+}
+
+// Format implements fmt.Formatter.
+func (e BitStringEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+// HexDumpEnc formats B by HexDumper.
+type HexDumpEnc struct {
+	B []byte
+	// Dumper options, the zero value is HexDump
+	Dumper HexDumper
+}
+
+// String returns the hexdump of B field without trailing newline.
+func (e HexDumpEnc) String() string {
+	return e.Dumper.Dump(e.B)
+}
+
+// Format implements fmt.Formatter.
+func (e HexDumpEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+//============================================================================
+
+// TruncateEnc truncates long S string, e.g. a response body.
+type TruncateEnc struct {
+	S string
+	// Maximum length in bytes, zero is TRUNCATE_DEFAULT
+	Max int
+}
+
+// String returns S truncated at UTF-8 character boundary followed by the
+// number of truncated bytes, like ``abc...(+1234 bytes)''.
+func (e TruncateEnc) String() string {
+	max := e.Max
+	if max <= 0 {
+		max = TRUNCATE_DEFAULT
+	}
+	if len(e.S) <= max {
+		return e.S
+	}
+	i := max
+	for i > 0 && !utf8.RuneStart(e.S[i]) {
+		i--
+	}
+	return fmt.Sprintf("%s...(+%d bytes)", e.S[:i], len(e.S)-i)
+}
+
+// Format implements fmt.Formatter.
+func (e TruncateEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+// RedactEnc hides secret S string, like a password or token.
+type RedactEnc struct {
+	S string
+	// Number of trailing characters to show, they are only shown if S has
+	// more than twice as many characters.
+	Show int
+}
+
+// String returns REDACT_MASK followed by the shown characters, like
+// ``****1234''. Empty S is returned as is, to tell that it is missing.
+func (e RedactEnc) String() string {
+	if e.S == "" {
+		return ""
+	}
+	r := []rune(e.S)
+	if e.Show <= 0 || len(r) <= 2*e.Show {
+		return REDACT_MASK
+	}
+	return REDACT_MASK + string(r[len(r)-e.Show:])
+}
+
+// Format implements fmt.Formatter.
+func (e RedactEnc) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e, e.String())
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, so the
+// secret doesn't leak through JSON encoding.
+func (e RedactEnc) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
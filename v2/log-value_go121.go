@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+package util
+
+import (
+	"log/slog"
+)
+
+// LogValue implements slog.LogValuer.
+func (e JsonEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (h hexWrapper) LogValue() slog.Value {
+	return slog.StringValue(h.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (e JsonIndentEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (e KeyValueEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (e BitStringEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (e HexDumpEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// LogValue implements slog.LogValuer.
+func (e TruncateEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// LogValue implements slog.LogValuer, the secret never reaches the handler.
+func (e RedactEnc) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
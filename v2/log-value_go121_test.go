@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package util_test
+
+import (
+	"bytes"
+	. "github.com/hanindo/util/v2"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lazy log values slog", func() {
+	It("should log values", func() {
+		var buf bytes.Buffer
+		log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))
+		log.Info("login", "user", JsonEnc{"abc"},
+			"password", RedactEnc{S: "secret"},
+			"body", TruncateEnc{S: "abcdef", Max: 2},
+			"raw", HexWrapper([]byte{1, 2}, "[", "]", "", 80, 80),
+			"flags", BitStringEnc{[]byte{0x0F}},
+			"kv", KeyValueEnc{map[string]int{"a": 1}},
+			"indent", JsonIndentEnc{V: 1},
+			"dump", HexDumpEnc{B: []byte{}})
+		Expect(buf.String()).To(Equal(`level=INFO msg=login user="\"abc\"" ` +
+			`password=**** body="ab...(+4 bytes)" raw="[.1 .2]" ` +
+			`flags="0000 1111" kv="a: 1" indent=1 dump=""` + "\n"))
+	})
+})
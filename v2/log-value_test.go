@@ -0,0 +1,111 @@
+package util_test
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/hanindo/util/v2"
+	"math"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lazy log values", func() {
+	DescribeTable("Format", func(format string, v interface{}, x string) {
+		Expect(fmt.Sprintf(format, v)).To(Equal(x))
+	},
+		Entry("v", "%v", JsonEnc{[]int{1, 2}}, "[1,2]"),
+		Entry("s width", "%-7s|", JsonEnc{[]int{1, 2}}, "[1,2]  |"),
+		Entry("q", "%q", JsonEnc{"a"}, `"\"a\""`),
+		Entry("precision", "%.3v", TruncateEnc{S: "abcdef"}, "abc"),
+		Entry("sharp v", "%#v", RedactEnc{S: "secret"}, "****"),
+		Entry("plus v", "%+v", RedactEnc{S: "secret"}, "****"),
+		Entry("bad verb", "%d", JsonEnc{1}, "%!d(util.JsonEnc)"),
+		Entry("HexWrapper", "%10v", HexWrapper([]byte{1}, "[", "]", "", 80, 80),
+			"      [.1]"),
+		Entry("BitStringEnc", "%v", BitStringEnc{[]byte{0xA5}}, "1010 0101"),
+		Entry("HexDumpEnc", "%v", HexDumpEnc{B: []byte("Hi")},
+			"00000000  48 69                                             |Hi|"),
+	)
+
+	It("should return JSON error", func() {
+		Expect(JsonIndentEnc{V: math.Inf(1)}.String()).To(
+			Equal("!(json: unsupported value: +Inf)"))
+		Expect(KeyValueEnc{make(chan int)}.String()).To(
+			Equal("!(json: unsupported type: chan int)"))
+	})
+
+	It("should indent JSON", func() {
+		v := map[string]interface{}{"a": []int{1}}
+		Expect(JsonIndentEnc{V: v}.String()).To(Equal("{\n  \"a\": [\n    1\n  ]\n}"))
+		Expect(JsonIndentEnc{V: v, Indent: "\t"}.String()).To(
+			Equal("{\n\t\"a\": [\n\t\t1\n\t]\n}"))
+	})
+
+	DescribeTable("KeyValueEnc", func(v interface{}, x string) {
+		Expect(KeyValueEnc{v}.String()).To(Equal(x))
+	},
+		Entry("scalar", 12, "12"),
+		Entry("string", "abc", "abc"),
+		Entry("null", nil, "null"),
+		Entry("struct", struct {
+			Name  string            `json:"name"`
+			Tags  []string          `json:"tags"`
+			Point map[string]int    `json:"point"`
+			Empty map[string]string `json:"empty"`
+			List  []int             `json:"list"`
+			Quote string            `json:"quote"`
+			Ok    bool              `json:"ok"`
+		}{"abc", []string{"a", "b"}, map[string]int{"y": 2, "x": 1},
+			map[string]string{}, []int{}, "a: b", true},
+			`name: abc
+tags:
+  - a
+  - b
+point:
+  x: 1
+  y: 2
+empty: {}
+list: []
+quote: "a: b"
+ok: true`),
+		Entry("array of objects", []interface{}{
+			map[string]int{"a": 1, "b": 2}, []int{3, 4}, "12", "-x", ""},
+			`- a: 1
+  b: 2
+- - 3
+  - 4
+- "12"
+- "-x"
+- ""`),
+	)
+
+	DescribeTable("TruncateEnc", func(s string, max int, x string) {
+		Expect(TruncateEnc{S: s, Max: max}.String()).To(Equal(x))
+	},
+		Entry("short", "abc", 3, "abc"),
+		Entry("long", "abcdef", 3, "abc...(+3 bytes)"),
+		Entry("utf8", "aé", 2, "a...(+2 bytes)"),
+		Entry("default", strings.Repeat("a", 257), 0,
+			strings.Repeat("a", 256)+"...(+1 bytes)"),
+	)
+
+	DescribeTable("RedactEnc", func(s string, show int, x string) {
+		Expect(RedactEnc{S: s, Show: show}.String()).To(Equal(x))
+	},
+		Entry("hidden", "secret", 0, "****"),
+		Entry("show", "0812345678", 4, "****5678"),
+		Entry("too short", "12345678", 4, "****"),
+		Entry("empty", "", 4, ""),
+	)
+
+	It("should not leak secret in JSON", func() {
+		b, err := json.Marshal(struct {
+			Password RedactEnc `json:"password"`
+		}{RedactEnc{S: "secret"}})
+		Expect(err).To(Succeed())
+		Expect(string(b)).To(Equal(`{"password":"****"}`))
+	})
+})
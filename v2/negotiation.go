@@ -0,0 +1,210 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Negotiator picks the highest protocol version supported by both ends of a
+// connection, e.g. at device handshake.
+//
+// The struct can be loaded from configuration file, the constraints and
+// versions are encoded as text, e.g.
+//     {
+//       "supported": [">=1.0.0 <3.0.0"],
+//       "minimum": "1.2.0",
+//       "deprecated": [{"range": "1.x", "until": "2022-06-30T00:00:00Z"}]
+//     }
+type Negotiator struct {
+	// Locally supported version ranges, a version is supported if it
+	// satisfies any of them. Empty list supports all versions.
+	Supported []Constraint `json:"supported,omitempty"`
+	// Versions before Minimum are rejected, the zero value has no effect.
+	Minimum Version `json:"minimum"`
+	// Deprecation windows of supported versions
+	Deprecated []Deprecation `json:"deprecated,omitempty"`
+	// Clock used to check the deprecation windows, nil for real-time clock.
+	Clock Clock `json:"-"`
+}
+
+// Deprecation represents a deprecation window of a version range.
+//
+// Versions in Range are deprecated from Since, and rejected from Until.
+// The nil Since deprecates the versions right away, and the nil Until
+// keeps accepting them.
+type Deprecation struct {
+	Range Constraint `json:"range"`
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// RejectReason is the reason a peer version is rejected.
+type RejectReason int
+
+const (
+	// The version doesn't satisfy any supported range
+	REJECT_UNSUPPORTED RejectReason = iota
+	// The version is before the minimum version
+	REJECT_MINIMUM
+	// The version is past its deprecation window
+	REJECT_REMOVED
+)
+
+// String returns a string describing the reason.
+func (r RejectReason) String() string {
+	switch r {
+	case REJECT_UNSUPPORTED:
+		return "unsupported"
+	case REJECT_MINIMUM:
+		return "below minimum"
+	case REJECT_REMOVED:
+		return "removed"
+	}
+	return fmt.Sprintf("RejectReason(%d)", int(r))
+}
+
+// RejectedVersion is a peer version rejected by Negotiator.
+type RejectedVersion struct {
+	Version Version
+	Reason  RejectReason
+}
+
+// IncompatibleError is returned by Negotiate when no peer version is
+// acceptable. It records every advertised version and why it is rejected,
+// so the caller can report it back to the peer.
+type IncompatibleError struct {
+	Supported []Constraint
+	Minimum   Version
+	Rejected  []RejectedVersion
+}
+
+// Error returns the error message, e.g.
+//     No compatible version: 0.9.0 below minimum 1.2.0, 3.0.0 unsupported by ">=1.0.0 <3.0.0"
+func (e *IncompatibleError) Error() string {
+	if len(e.Rejected) == 0 {
+		return "No compatible version: peer advertised no version"
+	}
+
+	ss := make([]string, len(e.Rejected))
+	for i, r := range e.Rejected {
+		switch r.Reason {
+		case REJECT_MINIMUM:
+			ss[i] = fmt.Sprintf("%s %s %s", r.Version, r.Reason, e.Minimum)
+		case REJECT_UNSUPPORTED:
+			cs := make([]string, len(e.Supported))
+			for j, c := range e.Supported {
+				cs[j] = fmt.Sprintf("%q", c)
+			}
+			ss[i] = fmt.Sprintf("%s %s by %s", r.Version, r.Reason,
+				strings.Join(cs, " or "))
+		default:
+			ss[i] = fmt.Sprintf("%s %s", r.Version, r.Reason)
+		}
+	}
+	return "No compatible version: " + strings.Join(ss, ", ")
+}
+
+func (n Negotiator) now() time.Time {
+	if n.Clock == nil {
+		return time.Now()
+	}
+	return n.Clock.Now()
+}
+
+// check returns whether v is acceptable at t time, and the reason if not.
+func (n Negotiator) check(v Version, t time.Time) (RejectReason, bool) {
+	if v.Before(n.Minimum) {
+		return REJECT_MINIMUM, false
+	}
+
+	ok := len(n.Supported) == 0
+	for _, c := range n.Supported {
+		if c.Satisfies(v) {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return REJECT_UNSUPPORTED, false
+	}
+
+	for _, d := range n.Deprecated {
+		if d.Until != nil && !t.Before(*d.Until) && d.Range.Satisfies(v) {
+			return REJECT_REMOVED, false
+		}
+	}
+	return 0, true
+}
+
+// Check returns *IncompatibleError if v is not acceptable, or nil if it is.
+func (n Negotiator) Check(v Version) error {
+	if r, ok := n.check(v, n.now()); !ok {
+		return &IncompatibleError{
+			Supported: n.Supported,
+			Minimum:   n.Minimum,
+			Rejected:  []RejectedVersion{{v, r}},
+		}
+	}
+	return nil
+}
+
+// Negotiate returns the highest version in peer versions that is acceptable.
+// It returns *IncompatibleError if there is none.
+func (n Negotiator) Negotiate(peer []Version) (Version, error) {
+	t := n.now()
+	var agreed Version
+	var found bool
+	var rejected []RejectedVersion
+	for _, v := range peer {
+		if r, ok := n.check(v, t); !ok {
+			rejected = append(rejected, RejectedVersion{v, r})
+		} else if !found || agreed.Before(v) {
+			agreed, found = v, true
+		}
+	}
+
+	if !found {
+		return Version{}, &IncompatibleError{
+			Supported: n.Supported,
+			Minimum:   n.Minimum,
+			Rejected:  rejected,
+		}
+	}
+	return agreed, nil
+}
+
+// Deprecation returns the deprecation window of v that is currently in
+// effect, it returns false if v is not deprecated.
+func (n Negotiator) Deprecation(v Version) (Deprecation, bool) {
+	t := n.now()
+	for _, d := range n.Deprecated {
+		if (d.Since == nil || !t.Before(*d.Since)) && d.Range.Satisfies(v) {
+			return d, true
+		}
+	}
+	return Deprecation{}, false
+}
+
+// ParseVersionList parses comma or space separated versions, such as
+// advertised in handshake message, e.g. ``1.0.0, 1.2.0 2.0.0''.
+func ParseVersionList(s string) (Versions, error) {
+	fs := strings.Fields(strings.ReplaceAll(s, ",", " "))
+	vs := make(Versions, len(fs))
+	for i, f := range fs {
+		if err := vs[i].UnmarshalText([]byte(f)); err != nil {
+			return nil, err
+		}
+	}
+	return vs, nil
+}
+
+// FormatVersionList returns vs formatted as comma separated versions,
+// this is the inverse of ParseVersionList.
+func FormatVersionList(vs []Version) string {
+	ss := make([]string, len(vs))
+	for i, v := range vs {
+		ss[i] = v.String()
+	}
+	return strings.Join(ss, ",")
+}
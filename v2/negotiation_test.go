@@ -0,0 +1,121 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Negotiator", func() {
+	now := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	negotiator := func() Negotiator {
+		var n Negotiator
+		Expect(json.Unmarshal([]byte(`{
+			"supported": [">=1.0.0 <3.0.0", "^5.1"],
+			"minimum": "1.2.0",
+			"deprecated": [
+				{"range": "1.x", "until": "2022-02-01T00:00:00Z"},
+				{"range": "2.0.x", "since": "2022-01-01T00:00:00Z",
+					"until": "2022-06-01T00:00:00Z"},
+				{"range": "2.1.x", "since": "2022-06-01T00:00:00Z"}
+			]
+		}`), &n)).To(Succeed())
+		n.Clock = NewMockClock(now)
+		return n
+	}
+
+	versions := func(s string) Versions {
+		vs, err := ParseVersionList(s)
+		Expect(err).To(Succeed(), s)
+		return vs
+	}
+
+	DescribeTable("Negotiate",
+		func(peer, x string) {
+			v, err := negotiator().Negotiate(versions(peer))
+			Expect(err).To(Succeed())
+			Expect(v.String()).To(Equal(x))
+		},
+		Entry("single", "2.1.0", "2.1.0"),
+		Entry("highest", "2.0.0, 2.1.0, 2.0.5", "2.1.0"),
+		Entry("skip unsupported", "2.1.0 3.0.0 4.0.0", "2.1.0"),
+		Entry("second range", "2.1.0 5.2.0", "5.2.0"),
+		Entry("deprecated", "1.5.0 2.0.3", "2.0.3"),
+	)
+
+	It("should reject incompatible peer", func() {
+		_, err := negotiator().Negotiate(versions("1.1.0 1.5.0 3.0.0"))
+		Expect(err).To(MatchError(`No compatible version: ` +
+			`1.1.0 below minimum 1.2.0, 1.5.0 removed, ` +
+			`3.0.0 unsupported by ">=1.0.0 <3.0.0" or "^5.1"`))
+
+		var ie *IncompatibleError
+		Expect(err).To(BeAssignableToTypeOf(ie))
+		ie = err.(*IncompatibleError)
+		Expect(ie.Minimum).To(Equal(MakeVersion(1, 2, 0)))
+		Expect(ie.Rejected).To(Equal([]RejectedVersion{
+			{MakeVersion(1, 1, 0), REJECT_MINIMUM},
+			{MakeVersion(1, 5, 0), REJECT_REMOVED},
+			{MakeVersion(3, 0, 0), REJECT_UNSUPPORTED},
+		}))
+	})
+
+	It("should reject empty peer", func() {
+		_, err := negotiator().Negotiate(nil)
+		Expect(err).To(MatchError(
+			"No compatible version: peer advertised no version"))
+	})
+
+	It("should check single version", func() {
+		n := negotiator()
+		Expect(n.Check(MakeVersion(2, 0, 0))).To(Succeed())
+		Expect(n.Check(MakeVersion(1, 9, 0))).To(MatchError(
+			"No compatible version: 1.9.0 removed"))
+	})
+
+	It("should accept all versions without supported ranges", func() {
+		var n Negotiator
+		Expect(n.Negotiate(versions("0.0.1 9.9.9"))).
+			To(Equal(MakeVersion(9, 9, 9)))
+	})
+
+	It("should report deprecation window", func() {
+		n := negotiator()
+		d, ok := n.Deprecation(MakeVersion(2, 0, 1))
+		Expect(ok).To(BeTrue())
+		Expect(d.Range.String()).To(Equal("2.0.x"))
+		Expect(*d.Until).To(Equal(time.Date(2022, time.June, 1, 0, 0, 0, 0,
+			time.UTC)))
+
+		_, ok = n.Deprecation(MakeVersion(2, 1, 0))
+		Expect(ok).To(BeFalse())
+
+		n.Clock = NewMockClock(now.AddDate(0, 6, 0))
+		_, ok = n.Deprecation(MakeVersion(2, 1, 0))
+		Expect(ok).To(BeTrue())
+		Expect(n.Check(MakeVersion(2, 0, 1))).To(HaveOccurred())
+	})
+
+	It("should encode deprecation window", func() {
+		n := negotiator()
+		Expect(json.Marshal(n.Deprecated[0])).To(MatchJSON(
+			`{"range":"1.x","until":"2022-02-01T00:00:00Z"}`))
+		Expect(json.Marshal(n.Deprecated[2])).To(MatchJSON(
+			`{"range":"2.1.x","since":"2022-06-01T00:00:00Z"}`))
+	})
+
+	It("should format version list", func() {
+		vs := versions(" 1.0.0,1.2.0  2.0.0 ")
+		Expect(vs).To(Equal(Versions{MakeVersion(1, 0, 0),
+			MakeVersion(1, 2, 0), MakeVersion(2, 0, 0)}))
+		Expect(FormatVersionList(vs)).To(Equal("1.0.0,1.2.0,2.0.0"))
+
+		_, err := ParseVersionList("1.0.0 1.x")
+		Expect(err).To(MatchError(`Invalid text for *util.Version: "1.x"`))
+	})
+})
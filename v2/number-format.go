@@ -0,0 +1,293 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Currency symbol of Indonesian rupiah.
+const RUPIAH = "Rp"
+
+// NegativeStyle is the notation of negative numbers.
+type NegativeStyle int
+
+const (
+	// Leading minus sign, like ``-1,234.50'' or ``-Rp1.234''
+	NEGATIVE_MINUS NegativeStyle = iota
+	// Accounting parentheses, like ``(1,234.50)''
+	NEGATIVE_PARENS
+	// Trailing minus sign, like ``1,234.50-''
+	NEGATIVE_SUFFIX
+)
+
+// NumberFormat is the locale convention of formatting numbers. The zero value
+// formats whole numbers without grouping.
+type NumberFormat struct {
+	// Thousands separator, empty disables the grouping
+	Thousands string
+	// Decimal mark, empty is ``.''
+	Decimal string
+	// Decimal places, negative is the shortest representation of float and
+	// the digits as is of integer and decimal string.
+	Decimals int
+	// Remove trailing fraction zeroes by TrimZero
+	TrimZero bool
+	Negative NegativeStyle
+	// Currency symbol including any space, like ``Rp'' or ``IDR ''
+	Symbol string
+	// Put the symbol after the number, like ``1.234 €'' of `` €'' symbol
+	SymbolAfter bool
+}
+
+// Number formats of FormatNumber locales.
+var (
+	NUMBER_EN = NumberFormat{Thousands: ",", Decimal: ".", Decimals: -1}
+	NUMBER_ID = NumberFormat{Thousands: ".", Decimal: ",", Decimals: -1}
+)
+
+// numberLocale returns the number format of locale, like ``id'' or
+// ``en-US''. The language code is case insensitive, unknown language is
+// NUMBER_EN.
+func numberLocale(locale string) NumberFormat {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "id" {
+		return NUMBER_ID
+	}
+	return NUMBER_EN
+}
+
+// FormatNumber formats v number in locale convention, ``id'' for Indonesian
+// or ``en'' for English, e.g. 1234567.5 is ``1.234.567,5'' in ``id''.
+// See NumberFormat.Format for the accepted values.
+func FormatNumber(v interface{}, locale string) string {
+	return numberLocale(locale).Format(v)
+}
+
+// FormatMoney formats v amount with two decimal places in locale convention,
+// prefixed by currency symbol, e.g. 1500000 is ``Rp1.500.000,00'' of RUPIAH
+// symbol in ``id''.
+func FormatMoney(v interface{}, symbol, locale string) string {
+	f := numberLocale(locale)
+	f.Decimals = 2
+	f.Symbol = symbol
+	return f.Format(v)
+}
+
+// ParseNumber parses s number formatted in locale convention.
+func ParseNumber(s, locale string) (float64, error) {
+	return numberLocale(locale).Parse(s)
+}
+
+// ParseMoney parses s amount formatted in locale convention with optional
+// currency symbol.
+func ParseMoney(s, symbol, locale string) (float64, error) {
+	f := numberLocale(locale)
+	f.Symbol = symbol
+	return f.Parse(s)
+}
+
+//============================================================================
+
+var decimalRE = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+
+// decimal returns the decimal mark.
+func (f NumberFormat) decimal() string {
+	if f.Decimal == "" {
+		return "."
+	}
+	return f.Decimal
+}
+
+// digits returns v number as plain decimal string of f decimal places, like
+// ``-1234.50'', or false if v isn't a number.
+func (f NumberFormat) digits(v interface{}) (string, bool) {
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case fmt.Stringer:
+		s = x.String()
+	default:
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			s = strconv.FormatInt(rv.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			s = strconv.FormatUint(rv.Uint(), 10)
+		case reflect.Float32, reflect.Float64:
+			x := rv.Float()
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return "", false
+			}
+			s = strconv.FormatFloat(x, 'f', -1, rv.Type().Bits())
+		default:
+			return "", false
+		}
+	}
+	if !decimalRE.MatchString(s) {
+		return "", false
+	}
+	return roundDigits(s, f.Decimals), true
+}
+
+// roundDigits rounds s decimal string half away from zero to d decimal
+// places like Round, or pads it with zeroes. Negative d only normalizes s.
+// Float is rounded from its shortest representation, so 0.125 is 0.13.
+func roundDigits(s string, d int) string {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	in, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		in, frac = s[:i], s[i+1:]
+	}
+	if d >= 0 && len(frac) < d {
+		frac += strings.Repeat("0", d-len(frac))
+	} else if d >= 0 && len(frac) > d {
+		up := frac[d] >= '5'
+		b := []byte(in + frac[:d])
+		for i := len(b) - 1; up && i >= 0; i-- {
+			if b[i] == '9' {
+				b[i] = '0'
+			} else {
+				b[i]++
+				up = false
+			}
+		}
+		if up {
+			b = append([]byte{'1'}, b...)
+		}
+		in, frac = string(b[:len(b)-d]), string(b[len(b)-d:])
+	}
+
+	in = strings.TrimLeft(in, "0")
+	if in == "" {
+		in = "0"
+	}
+	s = in
+	if frac != "" {
+		s += "." + frac
+	}
+	if neg && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+// Format returns v number formatted by f. The number is integer, float, or
+// decimal string or fmt.Stringer like ``-1234.5''. Other values, including
+// NaN and infinity, are formatted by fmt.Sprint as is.
+func (f NumberFormat) Format(v interface{}) string {
+	s, ok := f.digits(v)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if f.TrimZero {
+		s = TrimZero(s)
+	}
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	in, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		in, frac = s[:i], s[i+1:]
+	}
+	if f.Thousands != "" {
+		in = strings.ReplaceAll(commaThousands(in), ",", f.Thousands)
+	}
+	s = in
+	if frac != "" {
+		s += f.decimal() + frac
+	}
+	if f.SymbolAfter {
+		s += f.Symbol
+	} else {
+		s = f.Symbol + s
+	}
+
+	if !neg {
+		return s
+	}
+	switch f.Negative {
+	case NEGATIVE_PARENS:
+		return "(" + s + ")"
+	case NEGATIVE_SUFFIX:
+		return s + "-"
+	}
+	return "-" + s
+}
+
+// ParseDigits parses s number formatted by f into plain decimal string, like
+// ``-1234.50''. Any negative style, optional symbol and surrounding spaces
+// are accepted, the digits must be grouped by three if there is a thousands
+// separator.
+func (f NumberFormat) ParseDigits(s string) (string, error) {
+	t := strings.TrimSpace(s)
+	neg := false
+	switch {
+	case strings.HasPrefix(t, "(") && strings.HasSuffix(t, ")"):
+		neg, t = true, t[1:len(t)-1]
+	case strings.HasSuffix(t, "-"):
+		neg, t = true, t[:len(t)-1]
+	case strings.HasPrefix(t, "-"):
+		neg, t = true, t[1:]
+	}
+	if sym := strings.TrimSpace(f.Symbol); sym != "" {
+		t = strings.TrimSpace(t)
+		if strings.HasPrefix(t, sym) {
+			t = t[len(sym):]
+		} else {
+			t = strings.TrimSuffix(t, sym)
+		}
+		t = strings.TrimSpace(t)
+		if !neg && strings.HasPrefix(t, "-") {
+			neg, t = true, t[1:]
+		}
+	}
+
+	in, frac := t, ""
+	if i := strings.LastIndex(t, f.decimal()); i >= 0 {
+		in, frac = t[:i], t[i+len(f.decimal()):]
+		if frac == "" {
+			return "", fmt.Errorf("invalid number: %q", s)
+		}
+	}
+	if f.Thousands != "" && strings.Contains(in, f.Thousands) {
+		groups := strings.Split(in, f.Thousands)
+		for i, g := range groups {
+			if len(g) != 3 && (i > 0 || len(g) == 0 || len(g) > 3) {
+				return "", fmt.Errorf("invalid digit grouping: %q", s)
+			}
+		}
+		in = strings.Join(groups, "")
+	}
+	t = in
+	if frac != "" {
+		t += "." + frac
+	}
+	if !decimalRE.MatchString(t) || strings.HasPrefix(t, "+") ||
+		strings.HasPrefix(t, "-") {
+		return "", fmt.Errorf("invalid number: %q", s)
+	}
+	if neg {
+		t = "-" + t
+	}
+	return roundDigits(t, -1), nil
+}
+
+// Parse parses s number formatted by f, like ParseDigits.
+func (f NumberFormat) Parse(s string) (float64, error) {
+	d, err := f.ParseDigits(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(d, 64)
+}
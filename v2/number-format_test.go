@@ -0,0 +1,113 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type numberString string
+
+func (s numberString) String() string {
+	return string(s)
+}
+
+var _ = Describe("NumberFormat", func() {
+	DescribeTable("FormatNumber", func(v interface{}, locale, x string) {
+		Expect(FormatNumber(v, locale)).To(Equal(x))
+	},
+		Entry("int en", 1234567, "en", "1,234,567"),
+		Entry("int id", 1234567, "id", "1.234.567"),
+		Entry("negative", int64(-1234), "id", "-1.234"),
+		Entry("small", uint8(123), "id", "123"),
+		Entry("float en", 1234567.5, "en", "1,234,567.5"),
+		Entry("float id", 1234567.5, "id-ID", "1.234.567,5"),
+		Entry("float32", float32(0.1), "en", "0.1"),
+		Entry("decimal string", "-0012345.670", "ID", "-12.345,670"),
+		Entry("stringer", numberString("9876.5"), "en_US", "9,876.5"),
+		Entry("unknown locale", 1000, "fr", "1,000"),
+		Entry("NaN", math.NaN(), "en", "NaN"),
+		Entry("not number", "abc", "en", "abc"),
+		Entry("duration", 2*time.Second, "en", "2s"),
+	)
+
+	DescribeTable("FormatMoney", func(v interface{}, sym, locale, x string) {
+		Expect(FormatMoney(v, sym, locale)).To(Equal(x))
+	},
+		Entry("rupiah", 1500000, RUPIAH, "id", "Rp1.500.000,00"),
+		Entry("rupiah negative", -1500.5, RUPIAH, "id", "-Rp1.500,50"),
+		Entry("round", 0.125, "$", "en", "$0.13"),
+		Entry("round up", "999.995", "$", "en", "$1,000.00"),
+		Entry("negative zero", -0.001, "$", "en", "$0.00"),
+		Entry("spaced symbol", 12.3, "IDR ", "en", "IDR 12.30"),
+	)
+
+	DescribeTable("Format", func(f NumberFormat, v interface{}, x string) {
+		Expect(f.Format(v)).To(Equal(x))
+	},
+		Entry("zero value", NumberFormat{}, 1234.56, "1235"),
+		Entry("fixed", NumberFormat{Decimals: 3}, 1234, "1234.000"),
+		Entry("trim zero", NumberFormat{Thousands: ".", Decimal: ",",
+			Decimals: 2, TrimZero: true, Symbol: RUPIAH}, 1500000, "Rp1.500.000"),
+		Entry("trim zero fraction", NumberFormat{Decimals: 4, TrimZero: true},
+			1.5, "1.5"),
+		Entry("parens", NumberFormat{Thousands: ",", Decimals: 2,
+			Negative: NEGATIVE_PARENS, Symbol: "$"}, -1234.5, "($1,234.50)"),
+		Entry("suffix", NumberFormat{Thousands: ".", Decimal: ",",
+			Negative: NEGATIVE_SUFFIX}, -1234, "1.234-"),
+		Entry("symbol after", NumberFormat{Thousands: ".", Decimal: ",",
+			Decimals: 2, Symbol: " €", SymbolAfter: true}, 1234.5, "1.234,50 €"),
+	)
+
+	DescribeTable("ParseNumber", func(s, locale string, x float64) {
+		Expect(ParseNumber(s, locale)).To(Equal(x))
+	},
+		Entry("en", "1,234,567.5", "en", 1234567.5),
+		Entry("id", "1.234.567,5", "id", 1234567.5),
+		Entry("ungrouped", "1234567", "id", 1234567.0),
+		Entry("negative", " -1.234 ", "id", -1234.0),
+		Entry("parens", "(1,234.5)", "en", -1234.5),
+		Entry("suffix", "1.234-", "id", -1234.0),
+	)
+
+	DescribeTable("ParseMoney", func(s, sym, locale string, x float64) {
+		Expect(ParseMoney(s, sym, locale)).To(Equal(x))
+	},
+		Entry("rupiah", "Rp1.500.000,00", RUPIAH, "id", 1500000.0),
+		Entry("spaced", "Rp 1.500", RUPIAH, "id", 1500.0),
+		Entry("minus before symbol", "-Rp1.500,50", RUPIAH, "id", -1500.5),
+		Entry("minus after symbol", "Rp-1.500", RUPIAH, "id", -1500.0),
+		Entry("parens", "($1,234.50)", "$", "en", -1234.5),
+		Entry("without symbol", "1,234.50", "$", "en", 1234.5),
+	)
+
+	DescribeTable("ParseNumber error", func(s, locale, x string) {
+		_, err := ParseNumber(s, locale)
+		Expect(err).To(MatchError(x))
+	},
+		Entry("empty", "", "en", `invalid number: ""`),
+		Entry("letters", "12a", "en", `invalid number: "12a"`),
+		Entry("double sign", "--1", "en", `invalid number: "--1"`),
+		Entry("trailing decimal", "1,", "id", `invalid number: "1,"`),
+		Entry("grouping", "1,23,456", "en", `invalid digit grouping: "1,23,456"`),
+		Entry("wrong locale", "1,5", "en", `invalid digit grouping: "1,5"`),
+		Entry("leading separator", ".123", "id", `invalid digit grouping: ".123"`),
+	)
+
+	It("should parse digits", func() {
+		Expect(NUMBER_ID.ParseDigits("-001.234,500")).To(Equal("-1234.500"))
+		Expect(NUMBER_EN.ParseDigits("-0.00")).To(Equal("0.00"))
+	})
+
+	It("should roundtrip", func() {
+		f := NumberFormat{Thousands: ".", Decimal: ",", Decimals: 2,
+			Negative: NEGATIVE_PARENS, Symbol: RUPIAH}
+		for _, v := range []string{"0.00", "-1.50", "1234567.89", "-100.00"} {
+			Expect(f.ParseDigits(f.Format(v))).To(Equal(v))
+		}
+	})
+})
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Binary type tags of period types. The binary format of period types is
+// similar to Date, the bits are 4 bits type tag, 12 bits of year component,
+// 9 bits of period component (month, quarter or week minus one) and 7 bits of
+// time zone offset component.
+const (
+	year_month_tag   = 0xC
+	year_quarter_tag = 0xD
+	year_week_tag    = 0xE
+)
+
+// makeDay returns the date of y-m-d in tz time zone.
+func makeDay(y int, m time.Month, d int, tz TZ) Date {
+	return Date{tm: time.Date(y, m, d, 0, 0, 0, 0, tz.Location())}
+}
+
+// datesBetween returns all dates from first to last inclusive.
+func datesBetween(first, last Date) []Date {
+	y, m, d := first.tm.Date()
+	loc := first.tm.Location()
+	var ds []Date
+	for i := 0; ; i++ {
+		day := Date{tm: time.Date(y, m, d+i, 0, 0, 0, 0, loc)}
+		ds = append(ds, day)
+		if !day.tm.Before(last.tm) {
+			return ds
+		}
+	}
+}
+
+// divPeriod splits period index i into year and 1-based period number.
+func divPeriod(i, n int) (int, int) {
+	y, p := i/n, i%n
+	if p < 0 {
+		y, p = y-1, p+n
+	}
+	return y, p + 1
+}
+
+// comparePeriod compares two periods by year then period number.
+func comparePeriod(y1, p1, y2, p2 int) int {
+	switch {
+	case y1 < y2 || (y1 == y2 && p1 < p2):
+		return -1
+	case y1 > y2 || (y1 == y2 && p1 > p2):
+		return 1
+	}
+	return 0
+}
+
+// marshalPeriod encodes y year and p period number from 1 to max, the name
+// of the period is used in the error message.
+func marshalPeriod(tag byte, y int, name string, p, max int, tz TZ) (
+	[]byte, error,
+) {
+	if y < 0 || y > 4094 {
+		return nil, fmt.Errorf("year outside range 0-4094: %d", y)
+	}
+	if p < 1 || p > max {
+		return nil, fmt.Errorf("%s outside range 1-%d: %d", name, max, p)
+	}
+	z, err := tz.bits()
+	if err != nil {
+		return nil, err
+	}
+
+	n := uint32(tag) << 28
+	n |= uint32(y) << 16
+	n |= uint32(p-1) << 7
+	n |= uint32(z)
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, n)
+	return b, nil
+}
+
+func unmarshalPeriod(tag byte, b []byte) (y, p int, tz TZ, err error) {
+	if len(b) != 4 {
+		err = fmt.Errorf("invalid length: %d", len(b))
+		return
+	}
+
+	if b[0]>>4 != tag {
+		err = fmt.Errorf("invalid type bits: %04b", b[0]>>4)
+		return
+	}
+
+	y = int(b[0]&0x0F)<<8 | int(b[1])
+	if y > 4094 {
+		err = fmt.Errorf("invalid year: %d", y)
+		return
+	}
+	p = (int(b[2])<<1 | int(b[3]>>7)) + 1
+
+	tz, err = tzFromBits(b[3])
+	return
+}
@@ -0,0 +1,259 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Period", func() {
+	date := func(y int, m time.Month, d int) Date {
+		return MakeDate(time.Date(y, m, d, 12, 0, 0, 0,
+			time.FixedZone("", 7*3600)))
+	}
+
+	Describe("YearMonth", func() {
+		ym := MakeYearMonth(date(2021, time.February, 14))
+
+		It("should have components", func() {
+			Expect(ym.Year()).To(Equal(2021))
+			Expect(ym.Month()).To(Equal(time.February))
+			Expect(ym.TZ()).To(Equal(TZ(420)))
+			Expect(ym.YearQuarter().String()).To(Equal("2021-Q1 +07:00"))
+		})
+
+		It("should have first and last date", func() {
+			Expect(ym.First().String()).To(Equal("2021-02-01 +07:00"))
+			Expect(ym.Last().String()).To(Equal("2021-02-28 +07:00"))
+			Expect(ym.Dates()).To(HaveLen(28))
+			Expect(ym.Contains(date(2021, time.February, 28))).To(BeTrue())
+			Expect(ym.Contains(date(2021, time.March, 1))).To(BeFalse())
+		})
+
+		DescribeTable("AddMonths",
+			func(n int, x string) {
+				nm := ym.AddMonths(n)
+				Expect(nm.String()).To(Equal(x))
+				Expect(nm.Sub(ym)).To(Equal(n))
+			},
+			Entry("zero", 0, "2021-02 +07:00"),
+			Entry("next year", 11, "2022-01 +07:00"),
+			Entry("prev year", -2, "2020-12 +07:00"),
+			Entry("far", -24, "2019-02 +07:00"),
+		)
+
+		It("should iterate and compare", func() {
+			var ms []string
+			end := ym.AddMonths(3)
+			for m := ym; !m.After(end); m = m.Next() {
+				ms = append(ms, m.String()[:7])
+			}
+			Expect(ms).To(Equal([]string{
+				"2021-02", "2021-03", "2021-04", "2021-05",
+			}))
+			Expect(ym.Prev().Before(ym)).To(BeTrue())
+			Expect(ym.Compare(ym)).To(Equal(0))
+			Expect(ym.Equal(MakeYearMonth(date(2021, time.February, 1)))).
+				To(BeTrue())
+		})
+
+		It("should be encoded", func() {
+			b, err := json.Marshal(ym)
+			Expect(err).To(Succeed(), "json encode err")
+			Expect(string(b)).To(Equal(`"2021-02 +07:00"`), "json encode")
+
+			var nm YearMonth
+			Expect(json.Unmarshal(b, &nm)).To(Succeed(), "json decode err")
+			Expect(nm).To(Equal(ym), "json decode")
+
+			b, err = ym.MarshalBinary()
+			Expect(err).To(Succeed(), "binary encode err")
+			Expect(FancyHex(b)).To(Equal("c7 e5 .. dc"), "binary encode")
+
+			nm = YearMonth{}
+			Expect(nm.UnmarshalBinary(b)).To(Succeed(), "binary decode err")
+			Expect(nm).To(Equal(ym), "binary decode")
+		})
+
+		It("should not encode zero value", func() {
+			_, err := YearMonth{}.MarshalBinary()
+			Expect(err).To(MatchError("month outside range 1-12: 0"))
+		})
+
+		DescribeTable("invalid",
+			func(err string, b []byte) {
+				var nm YearMonth
+				Expect(nm.UnmarshalBinary(b)).To(MatchError(err))
+			},
+			Entry("length", "invalid length: 3", []byte{0xC7, 0xE5, 0x00}),
+			Entry("type", "invalid type bits: 1011",
+				[]byte{0xB7, 0xE5, 0x00, 0xDC}),
+			Entry("year", "invalid year: 4095",
+				[]byte{0xCF, 0xFF, 0x00, 0xDC}),
+			Entry("month", "invalid month: 13",
+				[]byte{0xC7, 0xE5, 0x06, 0x5C}),
+			Entry("timezone", "invalid timezone: 15:30",
+				[]byte{0xC7, 0xE5, 0x00, 0xFE}),
+		)
+
+		It("should not decode invalid text", func() {
+			var nm YearMonth
+			Expect(nm.UnmarshalText([]byte("2021-13 +07:00"))).
+				To(MatchError(`invalid text for *util.YearMonth: ` +
+					`"2021-13 +07:00"`))
+		})
+	})
+
+	Describe("YearQuarter", func() {
+		yq := MakeYearQuarter(date(2021, time.May, 14))
+
+		It("should have components", func() {
+			Expect(yq.Year()).To(Equal(2021))
+			Expect(yq.Quarter()).To(Equal(2))
+			Expect(yq.TZ()).To(Equal(TZ(420)))
+			Expect(yq.Months()).To(Equal([]YearMonth{
+				MakeYearMonth(date(2021, time.April, 1)),
+				MakeYearMonth(date(2021, time.May, 1)),
+				MakeYearMonth(date(2021, time.June, 1)),
+			}))
+		})
+
+		It("should have first and last date", func() {
+			Expect(yq.First().String()).To(Equal("2021-04-01 +07:00"))
+			Expect(yq.Last().String()).To(Equal("2021-06-30 +07:00"))
+			Expect(yq.Dates()).To(HaveLen(91))
+			Expect(yq.Contains(date(2021, time.June, 30))).To(BeTrue())
+			Expect(yq.Contains(date(2021, time.July, 1))).To(BeFalse())
+		})
+
+		DescribeTable("AddQuarters",
+			func(n int, x string) {
+				nq := yq.AddQuarters(n)
+				Expect(nq.String()).To(Equal(x))
+				Expect(nq.Sub(yq)).To(Equal(n))
+			},
+			Entry("zero", 0, "2021-Q2 +07:00"),
+			Entry("next year", 3, "2022-Q1 +07:00"),
+			Entry("prev year", -2, "2020-Q4 +07:00"),
+		)
+
+		It("should compare", func() {
+			Expect(yq.Prev().Before(yq)).To(BeTrue())
+			Expect(yq.Next().After(yq)).To(BeTrue())
+			Expect(yq.Equal(yq.Next().Prev())).To(BeTrue())
+		})
+
+		It("should be encoded", func() {
+			b, err := json.Marshal(yq)
+			Expect(err).To(Succeed(), "json encode err")
+			Expect(string(b)).To(Equal(`"2021-Q2 +07:00"`), "json encode")
+
+			var nq YearQuarter
+			Expect(json.Unmarshal(b, &nq)).To(Succeed(), "json decode err")
+			Expect(nq).To(Equal(yq), "json decode")
+
+			b, err = yq.MarshalBinary()
+			Expect(err).To(Succeed(), "binary encode err")
+			Expect(FancyHex(b)).To(Equal("d7 e5 .. dc"), "binary encode")
+
+			nq = YearQuarter{}
+			Expect(nq.UnmarshalBinary(b)).To(Succeed(), "binary decode err")
+			Expect(nq).To(Equal(yq), "binary decode")
+		})
+
+		It("should not encode zero value", func() {
+			_, err := YearQuarter{}.MarshalBinary()
+			Expect(err).To(MatchError("quarter outside range 1-4: 0"))
+		})
+
+		It("should not decode invalid quarter", func() {
+			var nq YearQuarter
+			Expect(nq.UnmarshalBinary([]byte{0xD7, 0xE5, 0x02, 0x5C})).
+				To(MatchError("invalid quarter: 5"))
+			Expect(nq.UnmarshalText([]byte("2021-Q5 +07:00"))).
+				To(HaveOccurred())
+		})
+	})
+
+	Describe("YearWeek", func() {
+		yw := MakeYearWeek(date(2021, time.January, 3))
+
+		It("should have components", func() {
+			Expect(yw.Year()).To(Equal(2020))
+			Expect(yw.Week()).To(Equal(53))
+			Expect(yw.TZ()).To(Equal(TZ(420)))
+		})
+
+		It("should have first and last date", func() {
+			Expect(yw.First().String()).To(Equal("2020-12-28 +07:00"))
+			Expect(yw.Last().String()).To(Equal("2021-01-03 +07:00"))
+			Expect(yw.Dates()).To(HaveLen(7))
+			Expect(yw.Contains(date(2020, time.December, 28))).To(BeTrue())
+			Expect(yw.Contains(date(2021, time.January, 4))).To(BeFalse())
+		})
+
+		DescribeTable("AddWeeks",
+			func(n int, x string) {
+				nw := yw.AddWeeks(n)
+				Expect(nw.String()).To(Equal(x))
+				Expect(nw.Sub(yw)).To(Equal(n))
+			},
+			Entry("zero", 0, "2020-W53 +07:00"),
+			Entry("next year", 1, "2021-W01 +07:00"),
+			Entry("prev", -53, "2019-W52 +07:00"),
+			Entry("far", 105, "2023-W01 +07:00"),
+		)
+
+		DescribeTable("WeeksInYear",
+			func(y, x int) {
+				Expect(WeeksInYear(y)).To(Equal(x))
+			},
+			Entry("2020", 2020, 53),
+			Entry("2021", 2021, 52),
+			Entry("2026", 2026, 53),
+		)
+
+		It("should compare", func() {
+			Expect(yw.Prev().Before(yw)).To(BeTrue())
+			Expect(yw.Next().After(yw)).To(BeTrue())
+			Expect(yw.Equal(yw.Next().Prev())).To(BeTrue())
+		})
+
+		It("should be encoded", func() {
+			b, err := json.Marshal(yw)
+			Expect(err).To(Succeed(), "json encode err")
+			Expect(string(b)).To(Equal(`"2020-W53 +07:00"`), "json encode")
+
+			var nw YearWeek
+			Expect(json.Unmarshal(b, &nw)).To(Succeed(), "json decode err")
+			Expect(nw).To(Equal(yw), "json decode")
+
+			b, err = yw.MarshalBinary()
+			Expect(err).To(Succeed(), "binary encode err")
+			Expect(FancyHex(b)).To(Equal("e7 e4 1a 5c"), "binary encode")
+
+			nw = YearWeek{}
+			Expect(nw.UnmarshalBinary(b)).To(Succeed(), "binary decode err")
+			Expect(nw).To(Equal(yw), "binary decode")
+		})
+
+		It("should not encode zero value", func() {
+			_, err := YearWeek{}.MarshalBinary()
+			Expect(err).To(MatchError("week outside range 1-52: 0"))
+		})
+
+		It("should not decode invalid week", func() {
+			var nw YearWeek
+			Expect(nw.UnmarshalBinary([]byte{0xE7, 0xE5, 0x1A, 0x5C})).
+				To(MatchError("invalid week: 2021-W53"))
+			Expect(nw.UnmarshalText([]byte("2021-W53 +07:00"))).
+				To(MatchError("invalid week: 2021-W53"))
+			Expect(nw.UnmarshalText([]byte("2021-W00 +07:00"))).
+				To(MatchError("invalid week: 2021-W00"))
+		})
+	})
+})
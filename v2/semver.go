@@ -0,0 +1,307 @@
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer represents Semantic Versioning 2.0.0 https://semver.org version.
+//
+// Unlike Version, the numeric components are not limited to uint8 and it
+// may have pre-release and build metadata identifiers, e.g.
+//     1.4.0-rc.1+build.7
+//
+// The binary format is the 3 bytes Version format if the version fits in it,
+// otherwise it uses the extended variable length format: unsigned varints of
+// major, minor and patch components, followed by the dot separated
+// pre-release and build metadata strings, each prefixed by its length in
+// unsigned varint. The extended format is at least 5 bytes long.
+type SemVer struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+	Pre   []string
+	Build []string
+}
+
+// ParseSemVer parses s as semantic version.
+// The leading ``v'' prefix is not accepted.
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+	core := s
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		build := core[i+1:]
+		core = core[:i]
+		v.Build = strings.Split(build, ".")
+		for _, id := range v.Build {
+			if !isSemVerIdent(id) {
+				return v, fmt.Errorf("Invalid build identifier: %q", id)
+			}
+		}
+	}
+	if i := strings.IndexByte(core, '-'); i >= 0 {
+		pre := core[i+1:]
+		core = core[:i]
+		v.Pre = strings.Split(pre, ".")
+		for _, id := range v.Pre {
+			if !isSemVerIdent(id) || (isNumeric(id) && len(id) > 1 &&
+				id[0] == '0') {
+				return v, fmt.Errorf("Invalid pre-release identifier: %q", id)
+			}
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("Invalid text for %T: %q", &v, s)
+	}
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, name := range []string{"major", "minor", "patch"} {
+		p := parts[i]
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return v, fmt.Errorf("Invalid %s version: %s", name, p)
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("Invalid %s version: %s", name, p)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// isSemVerIdent checks whether s is a non empty alphanumerics and hyphens.
+func isSemVerIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if !((c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') ||
+			(c >= 'a' && c <= 'z') || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumeric checks whether s is a non empty decimal digits.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SemVer returns v as semantic version.
+func (v Version) SemVer() SemVer {
+	return SemVer{
+		Major: uint64(v.Major),
+		Minor: uint64(v.Minor),
+		Patch: uint64(v.Patch),
+	}
+}
+
+// Version returns s as compact version. It returns error if s has
+// pre-release identifiers or any component doesn't fit in uint8,
+// the build metadata is dropped.
+func (s SemVer) Version() (Version, error) {
+	if len(s.Pre) > 0 {
+		return Version{}, fmt.Errorf("Unsupported pre-release: %s", s)
+	}
+	if s.Major > 255 || s.Minor > 255 || s.Patch > 255 {
+		return Version{}, fmt.Errorf("Component outside range 0-255: %s", s)
+	}
+	return MakeVersion(uint8(s.Major), uint8(s.Minor), uint8(s.Patch)), nil
+}
+
+// Compare returns -1, 0 or +1 if s precedence is lower, equal or higher
+// than o. The build metadata is ignored as defined by the specification.
+func (s SemVer) Compare(o SemVer) int {
+	switch {
+	case s.Major != o.Major:
+		return cmpUint64(s.Major, o.Major)
+	case s.Minor != o.Minor:
+		return cmpUint64(s.Minor, o.Minor)
+	case s.Patch != o.Patch:
+		return cmpUint64(s.Patch, o.Patch)
+	}
+
+	// A version without pre-release has higher precedence.
+	switch {
+	case len(s.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(s.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(s.Pre) && i < len(o.Pre); i++ {
+		if c := cmpSemVerIdent(s.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return cmpInt(len(s.Pre), len(o.Pre))
+}
+
+// cmpSemVerIdent compares pre-release identifiers, numeric identifiers are
+// compared numerically and always have lower precedence than alphanumeric.
+func cmpSemVerIdent(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+	switch {
+	case an && bn:
+		if len(a) != len(b) {
+			return cmpInt(len(a), len(b))
+		}
+	case an:
+		return -1
+	case bn:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func cmpUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func cmpInt(a, b int) int {
+	return cmpUint64(uint64(a), uint64(b))
+}
+
+// Less reports whether s precedence is lower than o.
+func (s SemVer) Less(o SemVer) bool {
+	return s.Compare(o) < 0
+}
+
+// Before reports whether s precedence is lower than o.
+// This is the same as Less, for symmetry with Version.Before.
+func (s SemVer) Before(o SemVer) bool {
+	return s.Less(o)
+}
+
+// Equal reports whether s and o have the same precedence,
+// the build metadata is ignored.
+func (s SemVer) Equal(o SemVer) bool {
+	return s.Compare(o) == 0
+}
+
+// IsPreRelease reports whether s has pre-release identifiers.
+func (s SemVer) IsPreRelease() bool {
+	return len(s.Pre) > 0
+}
+
+// String returns a string representing the version.
+func (s SemVer) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d.%d.%d", s.Major, s.Minor, s.Patch)
+	if len(s.Pre) > 0 {
+		b.WriteByte('-')
+		b.WriteString(strings.Join(s.Pre, "."))
+	}
+	if len(s.Build) > 0 {
+		b.WriteByte('+')
+		b.WriteString(strings.Join(s.Build, "."))
+	}
+	return b.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (s SemVer) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The version is expected exactly like String() format.
+func (s *SemVer) UnmarshalText(b []byte) (err error) {
+	*s, err = ParseSemVer(string(b))
+	return
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// See the documentation on the SemVer type for more details.
+func (s SemVer) MarshalBinary() ([]byte, error) {
+	if v, err := s.Version(); err == nil && len(s.Build) == 0 {
+		return v.MarshalBinary()
+	}
+
+	pre := strings.Join(s.Pre, ".")
+	build := strings.Join(s.Build, ".")
+	b := make([]byte, 0, 3*binary.MaxVarintLen64+len(pre)+len(build)+2)
+	var buf [binary.MaxVarintLen64]byte
+	for _, n := range []uint64{s.Major, s.Minor, s.Patch} {
+		b = append(b, buf[:binary.PutUvarint(buf[:], n)]...)
+	}
+	for _, str := range []string{pre, build} {
+		b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(str)))]...)
+		b = append(b, str...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// See the documentation on the SemVer type for more details.
+func (s *SemVer) UnmarshalBinary(b []byte) error {
+	if len(b) == 3 {
+		*s = MakeVersion(b[0], b[1], b[2]).SemVer()
+		return nil
+	} else if len(b) < 5 {
+		return fmt.Errorf("invalid length: %d", len(b))
+	}
+
+	var ns [3]uint64
+	var strs [2]string
+	i := 0
+	for j := range ns {
+		n, l := binary.Uvarint(b[i:])
+		if l <= 0 {
+			return fmt.Errorf("invalid varint at offset %d", i)
+		}
+		ns[j] = n
+		i += l
+	}
+	for j := range strs {
+		n, l := binary.Uvarint(b[i:])
+		if l <= 0 {
+			return fmt.Errorf("invalid varint at offset %d", i)
+		}
+		i += l
+		if n > uint64(len(b)-i) {
+			return fmt.Errorf("invalid string length at offset %d: %d",
+				i-l, n)
+		}
+		strs[j] = string(b[i : i+int(n)])
+		i += int(n)
+	}
+	if i != len(b) {
+		return fmt.Errorf("invalid trailing bytes at offset %d", i)
+	}
+
+	text := fmt.Sprintf("%d.%d.%d", ns[0], ns[1], ns[2])
+	if strs[0] != "" {
+		text += "-" + strs[0]
+	}
+	if strs[1] != "" {
+		text += "+" + strs[1]
+	}
+	v, err := ParseSemVer(text)
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
@@ -0,0 +1,147 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SemVer", func() {
+	parse := func(s string) SemVer {
+		v, err := ParseSemVer(s)
+		Expect(err).To(Succeed(), s)
+		return v
+	}
+
+	DescribeTable("ParseSemVer valid",
+		func(s string, x SemVer) {
+			v := parse(s)
+			Expect(v).To(Equal(x), "parse")
+			Expect(v.String()).To(Equal(s), "String")
+
+			b, err := json.Marshal(v)
+			Expect(err).To(Succeed(), "json encode err")
+			Expect(string(b)).To(Equal(`"`+s+`"`), "json encode")
+
+			var nv SemVer
+			Expect(json.Unmarshal(b, &nv)).To(Succeed(), "json decode err")
+			Expect(nv).To(Equal(v), "json decode")
+		},
+		Entry("core", "1.2.3", SemVer{Major: 1, Minor: 2, Patch: 3}),
+		Entry("wide", "256.1000.18446744073709551615",
+			SemVer{Major: 256, Minor: 1000, Patch: 18446744073709551615}),
+		Entry("pre-release", "1.4.0-rc.1",
+			SemVer{Major: 1, Minor: 4, Pre: []string{"rc", "1"}}),
+		Entry("build", "1.4.0+build.7",
+			SemVer{Major: 1, Minor: 4, Build: []string{"build", "7"}}),
+		Entry("both", "1.4.0-rc.1+build.007",
+			SemVer{Major: 1, Minor: 4, Pre: []string{"rc", "1"},
+				Build: []string{"build", "007"}}),
+		Entry("hyphen", "1.0.0-x-y-z.--",
+			SemVer{Major: 1, Pre: []string{"x-y-z", "--"}}),
+	)
+
+	DescribeTable("ParseSemVer invalid",
+		func(s, err string) {
+			_, e := ParseSemVer(s)
+			Expect(e).To(MatchError(err))
+		},
+		Entry("short", "1.2", `Invalid text for *util.SemVer: "1.2"`),
+		Entry("prefix", "v1.2.3", "Invalid major version: v1"),
+		Entry("leading zero", "1.02.3", "Invalid minor version: 02"),
+		Entry("overflow", "1.2.18446744073709551616",
+			"Invalid patch version: 18446744073709551616"),
+		Entry("empty pre", "1.2.3-", `Invalid pre-release identifier: ""`),
+		Entry("pre zero", "1.2.3-rc.01",
+			`Invalid pre-release identifier: "01"`),
+		Entry("build char", "1.2.3+a_b", `Invalid build identifier: "a_b"`),
+	)
+
+	It("should sort by precedence", func() {
+		ss := []string{
+			"1.0.0", "1.0.0-alpha", "1.0.0-rc.1", "1.0.0-beta.11",
+			"1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-alpha.1",
+			"1.0.0-beta.2", "2.1.1", "2.0.0", "2.1.0", "1.0.0-rc.1+b",
+		}
+		vs := make([]SemVer, len(ss))
+		for i, s := range ss {
+			vs[i] = parse(s)
+		}
+		sort.SliceStable(vs, func(i, j int) bool { return vs[i].Less(vs[j]) })
+		for i, v := range vs {
+			ss[i] = v.String()
+		}
+		Expect(ss).To(Equal([]string{
+			"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta",
+			"1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1",
+			"1.0.0-rc.1+b", "1.0.0", "2.0.0", "2.1.0", "2.1.1",
+		}))
+	})
+
+	It("should ignore build metadata on comparison", func() {
+		Expect(parse("1.0.0+a").Equal(parse("1.0.0+b"))).To(BeTrue())
+		Expect(parse("1.0.0+a").Compare(parse("1.0.0"))).To(Equal(0))
+		Expect(parse("1.0.0-1").Before(parse("1.0.0-a"))).To(BeTrue())
+		Expect(parse("1.0.0-rc").IsPreRelease()).To(BeTrue())
+	})
+
+	DescribeTable("Binary",
+		func(s, x string) {
+			b, err := parse(s).MarshalBinary()
+			Expect(err).To(Succeed(), "encode err")
+			Expect(FancyHex(b)).To(Equal(x), "encode")
+
+			var nv SemVer
+			Expect(nv.UnmarshalBinary(b)).To(Succeed(), "decode err")
+			Expect(nv.String()).To(Equal(s), "decode")
+		},
+		Entry("compact", "1.2.3", ".1 .2 .3"),
+		Entry("zero", "0.0.0", ".. .. .."),
+		Entry("wide", "1.256.3", ".1 8. .2 .3 .. .."),
+		Entry("pre-release", "1.2.3-rc.1", ".1 .2 .3 .4 72 63 2e 31 .."),
+		Entry("build", "1.2.3+b", ".1 .2 .3 .. .1 62"),
+	)
+
+	DescribeTable("UnmarshalBinary invalid",
+		func(err string, b []byte) {
+			var nv SemVer
+			Expect(nv.UnmarshalBinary(b)).To(MatchError(err))
+		},
+		Entry("short", "invalid length: 4", []byte{1, 2, 3, 0}),
+		Entry("varint", "invalid varint at offset 2",
+			[]byte{1, 2, 0x80, 0x80, 0x80}),
+		Entry("string length", "invalid string length at offset 3: 5",
+			[]byte{1, 2, 3, 5, 'r', 0}),
+		Entry("trailing", "invalid trailing bytes at offset 5",
+			[]byte{1, 2, 3, 0, 0, 0}),
+		Entry("identifier", `Invalid pre-release identifier: "r_c"`,
+			[]byte{1, 2, 3, 3, 'r', '_', 'c', 0}),
+	)
+
+	Describe("Version conversion", func() {
+		It("should convert from Version", func() {
+			Expect(MakeVersion(1, 2, 3).SemVer()).
+				To(Equal(SemVer{Major: 1, Minor: 2, Patch: 3}))
+		})
+
+		It("should convert to Version", func() {
+			Expect(parse("1.2.3+b.1").Version()).
+				To(Equal(MakeVersion(1, 2, 3)))
+		})
+
+		DescribeTable("should not convert",
+			func(s, err string) {
+				_, e := parse(s).Version()
+				Expect(e).To(MatchError(err))
+			},
+			Entry("pre-release", "1.2.3-rc.1",
+				"Unsupported pre-release: 1.2.3-rc.1"),
+			Entry("wide", "1.256.3",
+				"Component outside range 0-255: 1.256.3"),
+		)
+	})
+})
@@ -0,0 +1,549 @@
+package util
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Align is the horizontal alignment of a table column.
+type Align int
+
+const (
+	// Right for numbers, left for others
+	ALIGN_AUTO Align = iota
+	ALIGN_LEFT
+	ALIGN_RIGHT
+	ALIGN_CENTER
+)
+
+// TableStyle is the border style of a table.
+type TableStyle int
+
+const (
+	// Columns separated by two spaces, without borders
+	TABLE_PLAIN TableStyle = iota
+	// Borders of ``+'', ``-'' and ``|''
+	TABLE_ASCII
+	// Borders of box drawing characters
+	TABLE_UNICODE
+	// GitHub flavored Markdown table
+	TABLE_MARKDOWN
+	// RFC 4180 CSV without padding and wrapping
+	TABLE_CSV
+)
+
+// Column is a table column definition.
+type Column struct {
+	Header string
+	Align  Align
+	// Fixed width in characters, longer cells are word wrapped. Zero is the
+	// width of the widest cell, or the header on streaming output.
+	Width int
+	// Decimal places of float and decimal fmt.Stringer values like Decimal,
+	// zero is the shortest representation of float and the digits as is of
+	// decimal, unless Fixed.
+	Precision int
+	// Round to zero decimal places when Precision is zero
+	Fixed bool
+	// Remove trailing fraction zeroes of numbers by TrimZero
+	TrimZero bool
+	// Replace trailing fraction zeroes of numbers by SpaceZero, so the
+	// decimal points of right aligned numbers with the same Precision are
+	// aligned.
+	SpaceZero bool
+	// Separate the thousands of numbers by Comma
+	Comma bool
+}
+
+// tableCell is formatted cell value.
+type tableCell struct {
+	s   string
+	num bool
+}
+
+// format returns the cell of v value.
+func (c Column) format(v interface{}) tableCell {
+	switch x := v.(type) {
+	case nil:
+		return tableCell{}
+	case string:
+		return tableCell{s: x}
+	}
+	s, ok := c.number(v)
+	if !ok {
+		return tableCell{s: fmt.Sprint(v)}
+	}
+
+	if c.TrimZero {
+		s = TrimZero(s)
+	}
+	if c.SpaceZero {
+		s = SpaceZero(s)
+	}
+	if c.Comma {
+		s = commaThousands(s)
+	}
+	return tableCell{s: s, num: true}
+}
+
+// number returns v as plain number string, or false if v isn't a number.
+// Numeric fmt.Stringer is a number only if its String() output is decimal,
+// so Decimal is a number but time.Duration isn't.
+func (c Column) number(v interface{}) (string, bool) {
+	x, stringer := v.(fmt.Stringer)
+	if !stringer {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			return strconv.FormatInt(rv.Int(), 10), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64:
+			return strconv.FormatUint(rv.Uint(), 10), true
+		case reflect.Float32, reflect.Float64:
+			return strconv.FormatFloat(rv.Float(), 'f', c.places(),
+				rv.Type().Bits()), true
+		}
+		return "", false
+	}
+	if s := x.String(); decimalRE.MatchString(s) {
+		return roundDigits(s, c.places()), true
+	}
+	return "", false
+}
+
+// places returns the decimal places of numbers, -1 for the shortest.
+func (c Column) places() int {
+	if c.Precision < 0 || c.Precision == 0 && !c.Fixed {
+		return -1
+	}
+	return c.Precision
+}
+
+// commaThousands puts Comma between the thousands of s number.
+func commaThousands(s string) string {
+	start := strings.IndexAny(s, "0123456789")
+	end := strings.IndexAny(s, ". ")
+	if start < 0 {
+		return s
+	} else if end < 0 {
+		end = len(s)
+	}
+	var idxs []int
+	for i := end - 3; i > start; i -= 3 {
+		idxs = append([]int{i}, idxs...)
+	}
+	return Comma(s, idxs)
+}
+
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// wrapText splits s into lines of at most w characters, at spaces if
+// possible. Zero w only splits at newlines.
+func wrapText(s string, w int) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		if w <= 0 || textWidth(para) <= w {
+			lines = append(lines, para)
+			continue
+		}
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for textWidth(word) > w {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				r := []rune(word)
+				lines = append(lines, string(r[:w]))
+				word = string(r[w:])
+			}
+			switch {
+			case word == "":
+			case line == "":
+				line = word
+			case textWidth(line)+1+textWidth(word) <= w:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// cellText returns s escaped for st style, Markdown cell is a single line.
+func cellText(s string, st TableStyle) string {
+	if st == TABLE_MARKDOWN {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+	}
+	return s
+}
+
+// pad returns s aligned in w characters.
+func pad(s string, w int, a Align) string {
+	n := w - textWidth(s)
+	if n <= 0 {
+		return s
+	}
+	switch a {
+	case ALIGN_RIGHT:
+		return strings.Repeat(" ", n) + s
+	case ALIGN_CENTER:
+		return strings.Repeat(" ", n/2) + s + strings.Repeat(" ", n-n/2)
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+//============================================================================
+
+// tableBorder is the border characters of a style, each line is left, fill,
+// cross and right characters.
+type tableBorder struct {
+	top, sep, bottom [4]string
+	left, mid, right string
+}
+
+var tableBorders = map[TableStyle]tableBorder{
+	TABLE_PLAIN: {mid: "  "},
+	TABLE_ASCII: {
+		top:    [4]string{"+", "-", "+", "+"},
+		sep:    [4]string{"+", "-", "+", "+"},
+		bottom: [4]string{"+", "-", "+", "+"},
+		left:   "| ", mid: " | ", right: " |",
+	},
+	TABLE_UNICODE: {
+		top:    [4]string{"┌", "─", "┬", "┐"},
+		sep:    [4]string{"├", "─", "┼", "┤"},
+		bottom: [4]string{"└", "─", "┴", "┘"},
+		left:   "│ ", mid: " │ ", right: " │",
+	},
+	TABLE_MARKDOWN: {left: "| ", mid: " | ", right: " |"},
+}
+
+// tableRenderer writes the table lines with fixed column widths.
+type tableRenderer struct {
+	cols   []Column
+	widths []int
+	style  TableStyle
+	indent string
+	w      io.Writer
+	csv    *csv.Writer
+}
+
+func (r *tableRenderer) line(s string) error {
+	if r.style == TABLE_PLAIN {
+		s = strings.TrimRight(s, " ")
+	}
+	_, err := io.WriteString(r.w, r.indent+s+"\n")
+	return err
+}
+
+// border writes b border line, if any.
+func (r *tableRenderer) border(b [4]string) error {
+	if b[0] == "" {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString(b[0])
+	for i, w := range r.widths {
+		if i > 0 {
+			sb.WriteString(b[2])
+		}
+		sb.WriteString(strings.Repeat(b[1], w+2))
+	}
+	sb.WriteString(b[3])
+	return r.line(sb.String())
+}
+
+// row writes the cells, header cells are not numbers.
+func (r *tableRenderer) row(cells []tableCell) error {
+	if r.style == TABLE_CSV {
+		rec := make([]string, len(r.widths))
+		for i := range rec {
+			if i < len(cells) {
+				rec[i] = strings.TrimSpace(cells[i].s)
+			}
+		}
+		r.csv.Write(rec)
+		r.csv.Flush()
+		return r.csv.Error()
+	}
+
+	b := tableBorders[r.style]
+	lines := make([][]string, len(r.widths))
+	height := 1
+	for i, w := range r.widths {
+		if i >= len(cells) {
+			continue
+		}
+		s := cells[i].s
+		if r.style == TABLE_MARKDOWN {
+			w = 0
+		}
+		lines[i] = wrapText(cellText(s, r.style), w)
+		if len(lines[i]) > height {
+			height = len(lines[i])
+		}
+	}
+	for k := 0; k < height; k++ {
+		var sb strings.Builder
+		sb.WriteString(b.left)
+		for i, w := range r.widths {
+			if i > 0 {
+				sb.WriteString(b.mid)
+			}
+			s := ""
+			if k < len(lines[i]) {
+				s = lines[i][k]
+			}
+			a := r.cols[i].Align
+			if a == ALIGN_AUTO && i < len(cells) && cells[i].num {
+				a = ALIGN_RIGHT
+			}
+			sb.WriteString(pad(s, w, a))
+		}
+		sb.WriteString(b.right)
+		if err := r.line(sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// header writes the top border, header and its separator, if there is any
+// header.
+func (r *tableRenderer) header() error {
+	if r.style == TABLE_CSV {
+		r.csv = csv.NewWriter(r.w)
+	}
+	if err := r.border(tableBorders[r.style].top); err != nil {
+		return err
+	}
+	cells := make([]tableCell, len(r.cols))
+	has := false
+	for i, c := range r.cols {
+		cells[i].s = c.Header
+		has = has || c.Header != ""
+	}
+	if !has {
+		return nil
+	}
+	if err := r.row(cells); err != nil {
+		return err
+	}
+	if r.style != TABLE_MARKDOWN {
+		return r.border(tableBorders[r.style].sep)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("|")
+	for i, w := range r.widths {
+		switch r.cols[i].Align {
+		case ALIGN_LEFT:
+			sb.WriteString(":" + strings.Repeat("-", w+1))
+		case ALIGN_RIGHT:
+			sb.WriteString(strings.Repeat("-", w+1) + ":")
+		case ALIGN_CENTER:
+			sb.WriteString(":" + strings.Repeat("-", w) + ":")
+		default:
+			sb.WriteString(strings.Repeat("-", w+2))
+		}
+		sb.WriteString("|")
+	}
+	return r.line(sb.String())
+}
+
+func (r *tableRenderer) footer() error {
+	return r.border(tableBorders[r.style].bottom)
+}
+
+//============================================================================
+
+// Table renders rows of values in aligned columns, e.g. TABLE_ASCII style:
+//     +------+--------+
+//     | Item |  Price |
+//     +------+--------+
+//     | tea  |  1,500 |
+//     | cake | 12,250 |
+//     +------+--------+
+// Numbers, including decimal fmt.Stringer like Decimal, are formatted by the
+// column options. Strings and other values are formatted as is.
+type Table struct {
+	Columns []Column
+	Style   TableStyle
+	// Prefix of every line, like Indent
+	Indent string
+
+	rows [][]tableCell
+}
+
+// cols returns the columns of n cells, adding the missing columns.
+func (t *Table) cols(n int) []Column {
+	cols := t.Columns
+	for len(cols) < n {
+		cols = append(cols, Column{})
+	}
+	return cols
+}
+
+func formatRow(cols []Column, vals []interface{}) []tableCell {
+	cells := make([]tableCell, len(vals))
+	for i, v := range vals {
+		cells[i] = cols[i].format(v)
+	}
+	return cells
+}
+
+// AddRow adds a row of vals, missing values are empty. Values beyond the
+// columns get new columns without header.
+func (t *Table) AddRow(vals ...interface{}) {
+	t.rows = append(t.rows, formatRow(t.cols(len(vals)), vals))
+}
+
+// WriteTo writes the table with the added rows to w, the column widths fit
+// the widest cell unless the width is fixed.
+func (t *Table) WriteTo(w io.Writer) (int64, error) {
+	n := len(t.Columns)
+	for _, row := range t.rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	cols := t.cols(n)
+	widths := make([]int, n)
+	for i, c := range cols {
+		widths[i] = c.Width
+		if c.Width > 0 {
+			continue
+		}
+		for _, l := range strings.Split(cellText(c.Header, t.Style), "\n") {
+			if textWidth(l) > widths[i] {
+				widths[i] = textWidth(l)
+			}
+		}
+		for _, row := range t.rows {
+			if i >= len(row) {
+				continue
+			}
+			for _, l := range strings.Split(cellText(row[i].s, t.Style), "\n") {
+				if textWidth(l) > widths[i] {
+					widths[i] = textWidth(l)
+				}
+			}
+		}
+	}
+
+	cw := &countWriter{w: w}
+	r := t.renderer(cw, cols, widths)
+	if err := r.header(); err != nil {
+		return cw.n, err
+	}
+	for _, row := range t.rows {
+		if err := r.row(row); err != nil {
+			return cw.n, err
+		}
+	}
+	err := r.footer()
+	return cw.n, err
+}
+
+// String returns the rendered table without trailing newline.
+func (t *Table) String() string {
+	var sb strings.Builder
+	t.WriteTo(&sb)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (t *Table) renderer(w io.Writer, cols []Column,
+	widths []int) *tableRenderer {
+	if t.Style == TABLE_MARKDOWN {
+		for i := range widths {
+			if widths[i] < 3 {
+				widths[i] = 3
+			}
+		}
+	}
+	return &tableRenderer{cols: cols, widths: widths, style: t.Style,
+		indent: t.Indent, w: w}
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// TableWriter writes table rows as soon as they are added, see
+// Table.NewWriter.
+type TableWriter struct {
+	t       *Table
+	r       *tableRenderer
+	started bool
+}
+
+// NewWriter returns TableWriter that streams the rows to w instead of adding
+// them to the table. As the rows are not known in advance, the column width
+// is the fixed width, or the header width, and longer cells are wrapped.
+func (t *Table) NewWriter(w io.Writer) *TableWriter {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = c.Width
+		if widths[i] <= 0 {
+			for _, l := range strings.Split(cellText(c.Header, t.Style), "\n") {
+				if textWidth(l) > widths[i] {
+					widths[i] = textWidth(l)
+				}
+			}
+		}
+		if widths[i] <= 0 {
+			widths[i] = 1
+		}
+	}
+	return &TableWriter{t: t, r: t.renderer(w, t.Columns, widths)}
+}
+
+func (tw *TableWriter) start() error {
+	if tw.started {
+		return nil
+	}
+	tw.started = true
+	return tw.r.header()
+}
+
+// WriteRow writes a row of vals, the values beyond the columns are ignored.
+// The header is written before the first row.
+func (tw *TableWriter) WriteRow(vals ...interface{}) error {
+	if err := tw.start(); err != nil {
+		return err
+	}
+	if len(vals) > len(tw.t.Columns) {
+		vals = vals[:len(tw.t.Columns)]
+	}
+	return tw.r.row(formatRow(tw.t.Columns, vals))
+}
+
+// Close writes the header if no row was written, and the bottom border.
+// It doesn't close the underlying writer.
+func (tw *TableWriter) Close() error {
+	if err := tw.start(); err != nil {
+		return err
+	}
+	return tw.r.footer()
+}
@@ -0,0 +1,176 @@
+package util_test
+
+import (
+	"bytes"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Table", func() {
+	columns := []Column{
+		{Header: "Item"},
+		{Header: "Qty"},
+		{Header: "Price", Precision: 2, SpaceZero: true, Comma: true},
+	}
+	rows := [][]interface{}{
+		{"tea", 2, 1500.0},
+		{"cake", 12, 12250.5},
+		{"water", nil, 0.25},
+	}
+	newTable := func(style TableStyle) *Table {
+		t := &Table{Columns: columns, Style: style}
+		for _, r := range rows {
+			t.AddRow(r...)
+		}
+		return t
+	}
+
+	DescribeTable("styles", func(style TableStyle, x string) {
+		Expect(newTable(style).String()).To(Equal(x))
+	},
+		Entry("plain", TABLE_PLAIN, `Item   Qty  Price
+tea      2   1,500
+cake    12  12,250.5
+water            0.25`),
+		Entry("ascii", TABLE_ASCII, `+-------+-----+-----------+
+| Item  | Qty | Price     |
++-------+-----+-----------+
+| tea   |   2 |  1,500    |
+| cake  |  12 | 12,250.5  |
+| water |     |      0.25 |
++-------+-----+-----------+`),
+		Entry("unicode", TABLE_UNICODE, `┌───────┬─────┬───────────┐
+│ Item  │ Qty │ Price     │
+├───────┼─────┼───────────┤
+│ tea   │   2 │  1,500    │
+│ cake  │  12 │ 12,250.5  │
+│ water │     │      0.25 │
+└───────┴─────┴───────────┘`),
+		Entry("markdown", TABLE_MARKDOWN, `| Item  | Qty | Price     |
+|-------|-----|-----------|
+| tea   |   2 |  1,500    |
+| cake  |  12 | 12,250.5  |
+| water |     |      0.25 |`),
+		Entry("csv", TABLE_CSV, `Item,Qty,Price
+tea,2,"1,500"
+cake,12,"12,250.5"
+water,,0.25`),
+	)
+
+	It("should align and wrap", func() {
+		t := &Table{Style: TABLE_ASCII, Indent: "  ", Columns: []Column{
+			{Header: "Name", Align: ALIGN_CENTER},
+			{Header: "Note", Width: 10},
+			{Header: "N", Align: ALIGN_LEFT},
+		}}
+		t.AddRow("a", "the quick brown fox", 1)
+		t.AddRow("abcdef", "supercalifragilistic", 22, "extra")
+		Expect(t.String()).To(Equal(`  +--------+------------+----+-------+
+  |  Name  | Note       | N  |       |
+  +--------+------------+----+-------+
+  |   a    | the quick  | 1  |       |
+  |        | brown fox  |    |       |
+  | abcdef | supercalif | 22 | extra |
+  |        | ragilistic |    |       |
+  +--------+------------+----+-------+`))
+	})
+
+	It("should render markdown alignment and escape", func() {
+		t := &Table{Style: TABLE_MARKDOWN, Columns: []Column{
+			{Header: "L", Align: ALIGN_LEFT},
+			{Header: "C", Align: ALIGN_CENTER},
+			{Header: "R", Align: ALIGN_RIGHT},
+		}}
+		t.AddRow("a|b", "x", "y\nz")
+		Expect(t.String()).To(Equal(`| L    |  C  |   R |
+|:-----|:---:|----:|
+| a\|b |  x  | y z |`))
+	})
+
+	It("should format numbers", func() {
+		t := &Table{Columns: []Column{
+			{Precision: 3, TrimZero: true},
+			{Comma: true},
+			{},
+		}}
+		t.AddRow(1.5, -1234567, float32(0.1))
+		t.AddRow(2.0, uint8(7), true)
+		Expect(t.String()).To(Equal(`1.5  -1,234,567   0.1
+  2           7  true`))
+	})
+
+	It("should format numeric stringers", func() {
+		d, err := ParseDecimal("1234.5")
+		Expect(err).To(Succeed())
+		t := &Table{Columns: []Column{
+			{Precision: 2, Comma: true},
+			{Fixed: true},
+			{},
+		}}
+		t.AddRow(d, 2.6, 2*time.Second)
+		t.AddRow(numberString("-7"), 12.4, 90*time.Minute)
+		Expect(t.String()).To(Equal(`1,234.50   3  2s
+   -7.00  12  1h30m0s`))
+	})
+
+	It("should keep numbers of unconfigured columns", func() {
+		d, err := ParseDecimal("12.750")
+		Expect(err).To(Succeed())
+		t := &Table{}
+		t.AddRow("pi", 3.14159, d, float32(0.1))
+		Expect(t.String()).To(Equal("pi  3.14159  12.750  0.1"))
+	})
+
+	It("should stream rows", func() {
+		var buf bytes.Buffer
+		t := &Table{Style: TABLE_ASCII, Columns: []Column{
+			{Header: "Item"},
+			{Header: "Price", Width: 8, Precision: 2, SpaceZero: true},
+		}}
+		w := t.NewWriter(&buf)
+		Expect(buf.String()).To(BeEmpty())
+		Expect(w.WriteRow("tea", 1.5)).To(Succeed())
+		Expect(buf.String()).To(Equal(`+------+----------+
+| Item | Price    |
++------+----------+
+| tea  |     1.5  |
+`))
+		Expect(w.WriteRow("chocolate", 12.0, "ignored")).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+		Expect(buf.String()).To(HaveSuffix(`| tea  |     1.5  |
+| choc |    12    |
+| olat |          |
+| e    |          |
++------+----------+
+`))
+	})
+
+	It("should stream header only", func() {
+		var buf bytes.Buffer
+		w := (&Table{Style: TABLE_CSV, Columns: columns}).NewWriter(&buf)
+		Expect(w.Close()).To(Succeed())
+		Expect(buf.String()).To(Equal("Item,Qty,Price\n"))
+	})
+
+	It("should write to writer", func() {
+		var buf bytes.Buffer
+		n, err := newTable(TABLE_PLAIN).WriteTo(&buf)
+		Expect(err).To(Succeed())
+		Expect(n).To(Equal(int64(buf.Len())))
+
+		_, err = newTable(TABLE_ASCII).WriteTo(&failWriter{n: 2})
+		Expect(err).To(MatchError("write failed"))
+	})
+
+	It("should render without header", func() {
+		t := &Table{Style: TABLE_ASCII}
+		t.AddRow("a", 1)
+		Expect(t.String()).To(Equal(`+---+---+
+| a | 1 |
++---+---+`))
+	})
+})
@@ -0,0 +1,302 @@
+package util
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const tod_format = "15:04:05.999999999"
+
+// TimeOfDay represents a clock time without date and time zone, from
+// 00:00:00 up to 23:59:59.999999999. The zero value is midnight.
+//
+// This time can be encoded to 3 bytes using Temporenc https://temporenc.org
+// time type, the bits are 7 bits type tag ``0b1010000'' and 17 bits of time
+// component (h=5 + m=6 + s=6). If it has sub second component, 4 bytes of
+// big endian nanoseconds are appended.
+type TimeOfDay struct {
+	ns time.Duration
+}
+
+// MakeTimeOfDay composes a time of day from its components. The time is
+// normalized and wrapped around midnight, so 25:00 becomes 01:00.
+func MakeTimeOfDay(hour, min, sec, nsec int) TimeOfDay {
+	return TimeOfDay{}.Add(time.Duration(hour)*time.Hour +
+		time.Duration(min)*time.Minute +
+		time.Duration(sec)*time.Second +
+		time.Duration(nsec))
+}
+
+// TimeToTimeOfDay returns the clock time of t time in its location.
+func TimeToTimeOfDay(t time.Time) TimeOfDay {
+	h, m, s := t.Clock()
+	return MakeTimeOfDay(h, m, s, t.Nanosecond())
+}
+
+var timeOfDayRE = regexp.MustCompile(
+	`^([01]\d|2[0-3]):([0-5]\d)(?::([0-5]\d)(?:\.(\d{1,9}))?)?$`)
+
+// ParseTimeOfDay parses time formatted as ``15:04'', ``15:04:05'' or
+// ``15:04:05.999999999''.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	m := timeOfDayRE.FindStringSubmatch(s)
+	if m == nil {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day: %q", s)
+	}
+
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	var sec, ns int
+	if m[3] != "" {
+		sec, _ = strconv.Atoi(m[3])
+	}
+	if m[4] != "" {
+		ns, _ = strconv.Atoi(m[4] + strings.Repeat("0", 9-len(m[4])))
+	}
+	return MakeTimeOfDay(h, min, sec, ns), nil
+}
+
+// Hour returns the hour of t, in the range [0, 23].
+func (t TimeOfDay) Hour() int {
+	return int(t.ns / time.Hour)
+}
+
+// Minute returns the minute of t, in the range [0, 59].
+func (t TimeOfDay) Minute() int {
+	return int(t.ns % time.Hour / time.Minute)
+}
+
+// Second returns the second of t, in the range [0, 59].
+func (t TimeOfDay) Second() int {
+	return int(t.ns % time.Minute / time.Second)
+}
+
+// Nanosecond returns the nanosecond of t, in the range [0, 999999999].
+func (t TimeOfDay) Nanosecond() int {
+	return int(t.ns % time.Second)
+}
+
+// Duration returns the duration since midnight.
+func (t TimeOfDay) Duration() time.Duration {
+	return t.ns
+}
+
+// Add returns t plus d duration wrapped around midnight.
+func (t TimeOfDay) Add(d time.Duration) TimeOfDay {
+	ns := (t.ns + d%DAY) % DAY
+	if ns < 0 {
+		ns += DAY
+	}
+	return TimeOfDay{ns: ns}
+}
+
+// Sub returns the duration from o forward to t, wrapped around midnight, so
+// it is always in the range [0, 24h).
+func (t TimeOfDay) Sub(o TimeOfDay) time.Duration {
+	return TimeOfDay{}.Add(t.ns - o.ns).ns
+}
+
+// Before reports whether t is before o in the same day.
+func (t TimeOfDay) Before(o TimeOfDay) bool {
+	return t.ns < o.ns
+}
+
+// After reports whether t is after o in the same day.
+func (t TimeOfDay) After(o TimeOfDay) bool {
+	return t.ns > o.ns
+}
+
+// On returns the time of t on d date in the date's time zone.
+func (t TimeOfDay) On(d Date) time.Time {
+	y, m, day := d.tm.Date()
+	return time.Date(y, m, day, t.Hour(), t.Minute(), t.Second(),
+		t.Nanosecond(), d.tm.Location())
+}
+
+// String returns time formatted like ``15:04:05'', the sub second
+// component is only added if it is not zero.
+func (t TimeOfDay) String() string {
+	return time.Time{}.Add(t.ns).Format(tod_format)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// All ParseTimeOfDay formats are accepted.
+func (t *TimeOfDay) UnmarshalText(b []byte) (err error) {
+	*t, err = ParseTimeOfDay(string(b))
+	return
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// See the documentation on the TimeOfDay type for more details.
+func (t TimeOfDay) MarshalBinary() ([]byte, error) {
+	n := uint32(0x50) << 17
+	n |= uint32(t.Hour()) << 12
+	n |= uint32(t.Minute()) << 6
+	n |= uint32(t.Second())
+
+	b := make([]byte, 3, 7)
+	b[0], b[1], b[2] = byte(n>>16), byte(n>>8), byte(n)
+	if ns := t.Nanosecond(); ns != 0 {
+		b = b[:7]
+		binary.BigEndian.PutUint32(b[3:], uint32(ns))
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// See the documentation on the TimeOfDay type for more details.
+func (t *TimeOfDay) UnmarshalBinary(b []byte) error {
+	if len(b) != 3 && len(b) != 7 {
+		return fmt.Errorf("invalid length: %d", len(b))
+	}
+
+	if b[0]&0xFE != 0xA0 {
+		return fmt.Errorf("invalid type bits: %07b", b[0]>>1)
+	}
+
+	h := int(b[0]&0x01)<<4 | int(b[1]>>4)
+	if h > 23 {
+		return fmt.Errorf("invalid hour: %d", h)
+	}
+	m := int(b[1]&0x0F)<<2 | int(b[2]>>6)
+	if m > 59 {
+		return fmt.Errorf("invalid minute: %d", m)
+	}
+	s := int(b[2] & 0x3F)
+	if s > 59 {
+		return fmt.Errorf("invalid second: %d", s)
+	}
+
+	var ns int
+	if len(b) == 7 {
+		ns = int(binary.BigEndian.Uint32(b[3:]))
+		if ns > 999999999 {
+			return fmt.Errorf("invalid nanosecond: %d", ns)
+		}
+	}
+
+	*t = MakeTimeOfDay(h, m, s, ns)
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+// The time is stored as String() output.
+func (t TimeOfDay) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// It accepts string, []byte and time.Time values.
+func (t *TimeOfDay) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case string:
+		*t, err = ParseTimeOfDay(v)
+	case []byte:
+		*t, err = ParseTimeOfDay(string(v))
+	case time.Time:
+		*t = TimeToTimeOfDay(v)
+	default:
+		err = fmt.Errorf("cannot scan %T into %T", src, t)
+	}
+	return
+}
+
+//============================================================================
+
+// TimeRange represents a daily time range such as opening hours.
+//
+// The range includes Start and excludes End. If End is not after Start the
+// range spans overnight, e.g. ``22:00-06:00''. If both are equal the range
+// covers the whole day.
+type TimeRange struct {
+	Start TimeOfDay
+	End   TimeOfDay
+}
+
+// ParseTimeRange parses range formatted like ``08:00-17:00'', both times
+// can be any ParseTimeOfDay format.
+func ParseTimeRange(s string) (TimeRange, error) {
+	var r TimeRange
+	i := strings.IndexByte(s, '-')
+	if i < 0 {
+		return r, fmt.Errorf("invalid time range: %q", s)
+	}
+
+	var err error
+	if r.Start, err = ParseTimeOfDay(s[:i]); err != nil {
+		return r, fmt.Errorf("invalid time range: %q", s)
+	}
+	if r.End, err = ParseTimeOfDay(s[i+1:]); err != nil {
+		return r, fmt.Errorf("invalid time range: %q", s)
+	}
+	return r, nil
+}
+
+// Overnight reports whether r spans past midnight.
+func (r TimeRange) Overnight() bool {
+	return !r.End.After(r.Start)
+}
+
+// Duration returns the length of r.
+func (r TimeRange) Duration() time.Duration {
+	if r.Start == r.End {
+		return DAY
+	}
+	return r.End.Sub(r.Start)
+}
+
+// Contains reports whether t is in r.
+func (r TimeRange) Contains(t TimeOfDay) bool {
+	return t.Sub(r.Start) < r.Duration()
+}
+
+// ContainsTime reports whether the clock time of t is in r.
+func (r TimeRange) ContainsTime(t time.Time) bool {
+	return r.Contains(TimeToTimeOfDay(t))
+}
+
+// On returns the start and end time of r starting on d date,
+// the end will be on the next date for overnight range.
+func (r TimeRange) On(d Date) (start, end time.Time) {
+	start = r.Start.On(d)
+	end = r.End.On(d)
+	if r.Overnight() {
+		y, m, day := end.Date()
+		end = time.Date(y, m, day+1, end.Hour(), end.Minute(), end.Second(),
+			end.Nanosecond(), end.Location())
+	}
+	return
+}
+
+// String returns range formatted like ``08:00-17:00'', seconds are only added
+// if any of the times has seconds or sub second components.
+func (r TimeRange) String() string {
+	if r.Start.ns%time.Minute == 0 && r.End.ns%time.Minute == 0 {
+		return r.Start.String()[:5] + "-" + r.End.String()[:5]
+	}
+	return r.Start.String() + "-" + r.End.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (r TimeRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// All ParseTimeRange formats are accepted.
+func (r *TimeRange) UnmarshalText(b []byte) (err error) {
+	*r, err = ParseTimeRange(string(b))
+	return
+}
@@ -0,0 +1,213 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeOfDay", func() {
+	DescribeTable("Valid",
+		func(s string, x TimeOfDay, str, bin string) {
+			t, err := ParseTimeOfDay(s)
+			Expect(err).To(Succeed(), "parse err")
+			Expect(t).To(Equal(x), "parse")
+			Expect(t.String()).To(Equal(str), "String")
+
+			b, err := json.Marshal(t)
+			Expect(err).To(Succeed(), "json encode err")
+			Expect(string(b)).To(Equal(`"`+str+`"`), "json encode")
+
+			var nt TimeOfDay
+			Expect(json.Unmarshal(b, &nt)).To(Succeed(), "json decode err")
+			Expect(nt).To(Equal(t), "json decode")
+
+			b, err = t.MarshalBinary()
+			Expect(err).To(Succeed(), "binary encode err")
+			Expect(FancyHex(b)).To(Equal(bin), "binary encode")
+
+			nt = TimeOfDay{}
+			Expect(nt.UnmarshalBinary(b)).To(Succeed(), "binary decode err")
+			Expect(nt).To(Equal(t), "binary decode")
+		},
+		Entry("midnight", "00:00", TimeOfDay{}, "00:00:00", "a. .. .."),
+		Entry("minute", "08:30", MakeTimeOfDay(8, 30, 0, 0),
+			"08:30:00", "a. 87 8."),
+		Entry("second", "13:14:15", MakeTimeOfDay(13, 14, 15, 0),
+			"13:14:15", "a. d3 8f"),
+		Entry("fraction", "23:59:59.5", MakeTimeOfDay(23, 59, 59, 5e8),
+			"23:59:59.5", "a1 7e fb 1d cd 65 .."),
+		Entry("nano", "01:02:03.000000001", MakeTimeOfDay(1, 2, 3, 1),
+			"01:02:03.000000001", "a. 1. 83 .. .. .. .1"),
+	)
+
+	DescribeTable("ParseTimeOfDay invalid",
+		func(s string) {
+			_, err := ParseTimeOfDay(s)
+			Expect(err).To(MatchError(`invalid time of day: "` + s + `"`))
+		},
+		Entry("empty", ""),
+		Entry("hour", "24:00"),
+		Entry("minute", "12:60"),
+		Entry("second", "12:00:60"),
+		Entry("fraction", "12:00:00.1234567890"),
+	)
+
+	It("should have components", func() {
+		t := MakeTimeOfDay(13, 14, 15, 16)
+		Expect(t.Hour()).To(Equal(13))
+		Expect(t.Minute()).To(Equal(14))
+		Expect(t.Second()).To(Equal(15))
+		Expect(t.Nanosecond()).To(Equal(16))
+		Expect(t.Duration()).To(Equal(13*time.Hour + 14*time.Minute +
+			15*time.Second + 16))
+	})
+
+	DescribeTable("Add",
+		func(t TimeOfDay, d time.Duration, x string) {
+			Expect(t.Add(d).String()).To(Equal(x))
+		},
+		Entry("same day", MakeTimeOfDay(8, 0, 0, 0), 90*time.Minute,
+			"09:30:00"),
+		Entry("next day", MakeTimeOfDay(23, 0, 0, 0), 2*time.Hour,
+			"01:00:00"),
+		Entry("prev day", MakeTimeOfDay(1, 0, 0, 0), -2*time.Hour,
+			"23:00:00"),
+		Entry("days", MakeTimeOfDay(1, 0, 0, 0), 49*time.Hour, "02:00:00"),
+	)
+
+	It("should wrap MakeTimeOfDay", func() {
+		Expect(MakeTimeOfDay(25, 0, 0, 0)).To(Equal(MakeTimeOfDay(1, 0, 0, 0)))
+		Expect(MakeTimeOfDay(0, 0, 3723, 0).String()).To(Equal("01:02:03"))
+	})
+
+	It("should compare and subtract", func() {
+		a := MakeTimeOfDay(22, 0, 0, 0)
+		b := MakeTimeOfDay(6, 0, 0, 0)
+		Expect(a.Sub(b)).To(Equal(16 * time.Hour))
+		Expect(b.Sub(a)).To(Equal(8 * time.Hour))
+		Expect(b.Before(a)).To(BeTrue())
+		Expect(a.After(b)).To(BeTrue())
+	})
+
+	It("should combine with date", func() {
+		loc := time.FixedZone("", 7*3600)
+		d := MakeDate(time.Date(2021, time.February, 1, 23, 0, 0, 0, loc))
+		t := MakeTimeOfDay(13, 14, 15, 16).On(d)
+		Expect(t).To(Equal(time.Date(2021, time.February, 1, 13, 14, 15, 16,
+			loc)))
+		Expect(TimeToTimeOfDay(t)).To(Equal(MakeTimeOfDay(13, 14, 15, 16)))
+	})
+
+	DescribeTable("UnmarshalBinary invalid",
+		func(err string, b []byte) {
+			var t TimeOfDay
+			Expect(t.UnmarshalBinary(b)).To(MatchError(err))
+		},
+		Entry("length", "invalid length: 4", []byte{0xA0, 0, 0, 0}),
+		Entry("type", "invalid type bits: 1011000", []byte{0xB0, 0, 0}),
+		Entry("hour", "invalid hour: 24", []byte{0xA1, 0x80, 0x00}),
+		Entry("minute", "invalid minute: 60", []byte{0xA0, 0x0F, 0x00}),
+		Entry("second", "invalid second: 60", []byte{0xA0, 0x00, 0x3C}),
+		Entry("nanosecond", "invalid nanosecond: 1000000000",
+			[]byte{0xA0, 0, 0, 0x3B, 0x9A, 0xCA, 0x00}),
+	)
+
+	Describe("SQL", func() {
+		It("should be valued", func() {
+			Expect(MakeTimeOfDay(8, 0, 0, 0).Value()).To(Equal("08:00:00"))
+		})
+
+		DescribeTable("Scan",
+			func(src interface{}) {
+				var t TimeOfDay
+				Expect(t.Scan(src)).To(Succeed())
+				Expect(t).To(Equal(MakeTimeOfDay(8, 30, 0, 0)))
+			},
+			Entry("string", "08:30:00"),
+			Entry("bytes", []byte("08:30")),
+			Entry("time", time.Date(2000, time.January, 1, 8, 30, 0, 0,
+				time.UTC)),
+		)
+
+		It("should not scan other type", func() {
+			var t TimeOfDay
+			Expect(t.Scan(830)).
+				To(MatchError("cannot scan int into *util.TimeOfDay"))
+		})
+	})
+})
+
+var _ = Describe("TimeRange", func() {
+	tod := func(s string) TimeOfDay {
+		t, err := ParseTimeOfDay(s)
+		Expect(err).To(Succeed())
+		return t
+	}
+
+	DescribeTable("Contains",
+		func(s string, overnight bool, d time.Duration,
+			in, out []string) {
+			r, err := ParseTimeRange(s)
+			Expect(err).To(Succeed(), "parse err")
+			Expect(r.String()).To(Equal(s), "String")
+			Expect(r.Overnight()).To(Equal(overnight), "Overnight")
+			Expect(r.Duration()).To(Equal(d), "Duration")
+			for _, t := range in {
+				Expect(r.Contains(tod(t))).To(BeTrue(), t)
+			}
+			for _, t := range out {
+				Expect(r.Contains(tod(t))).To(BeFalse(), t)
+			}
+		},
+		Entry("office", "08:00-17:00", false, 9*time.Hour,
+			[]string{"08:00", "12:00", "16:59:59.999999999"},
+			[]string{"07:59:59", "17:00", "23:00"}),
+		Entry("overnight", "22:00-06:00", true, 8*time.Hour,
+			[]string{"22:00", "23:59:59", "00:00", "05:59"},
+			[]string{"06:00", "12:00", "21:59"}),
+		Entry("whole day", "00:00-00:00", true, 24*time.Hour,
+			[]string{"00:00", "12:00", "23:59:59"},
+			[]string{}),
+		Entry("seconds", "08:00:30-17:00:00", false,
+			9*time.Hour-30*time.Second,
+			[]string{"08:00:30"},
+			[]string{"08:00:29"}),
+	)
+
+	It("should have start and end time on date", func() {
+		loc := time.FixedZone("", 7*3600)
+		d := MakeDate(time.Date(2021, time.February, 28, 0, 0, 0, 0, loc))
+		r, _ := ParseTimeRange("22:00-06:00")
+		s, e := r.On(d)
+		Expect(s).To(Equal(time.Date(2021, time.February, 28, 22, 0, 0, 0,
+			loc)))
+		Expect(e).To(Equal(time.Date(2021, time.March, 1, 6, 0, 0, 0, loc)))
+		Expect(r.ContainsTime(e.Add(-1))).To(BeTrue())
+	})
+
+	It("should be encoded as JSON", func() {
+		r, _ := ParseTimeRange("08:00-17:00")
+		b, err := json.Marshal(r)
+		Expect(err).To(Succeed())
+		Expect(string(b)).To(Equal(`"08:00-17:00"`))
+
+		var nr TimeRange
+		Expect(json.Unmarshal(b, &nr)).To(Succeed())
+		Expect(nr).To(Equal(r))
+	})
+
+	DescribeTable("ParseTimeRange invalid",
+		func(s string) {
+			_, err := ParseTimeRange(s)
+			Expect(err).To(MatchError(`invalid time range: "` + s + `"`))
+		},
+		Entry("no dash", "08:00"),
+		Entry("start", "8:00-17:00"),
+		Entry("end", "08:00-25:00"),
+	)
+})
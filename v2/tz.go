@@ -0,0 +1,141 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// TZ represents a time zone offset in minutes east of UTC.
+//
+// Only multiples of 15 minutes between -16:00 and +15:15 are valid, so the
+// offset can be encoded to 7 bits as the number of quarter hours plus 64.
+// This is the same time zone component used by Date binary format.
+type TZ int
+
+// TimeToTZ returns the time zone offset of t time.
+func TimeToTZ(t time.Time) TZ {
+	_, offset := t.Zone()
+	return TZ(offset / 60)
+}
+
+var tzRE = regexp.MustCompile(`^([+-])(\d\d)(?::?(\d\d))?$`)
+
+// ParseTZ parses time zone offset formatted as ``Z'', ``+07'', ``+0700'' or
+// ``+07:00''. IANA time zone name such as ``Asia/Jakarta'' is also accepted
+// and will be resolved to its current offset.
+func ParseTZ(s string) (TZ, error) {
+	var tz TZ
+	if s == "Z" {
+		return tz, nil
+	}
+
+	if m := tzRE.FindStringSubmatch(s); m != nil {
+		h, _ := strconv.Atoi(m[2])
+		var min int
+		if m[3] != "" {
+			min, _ = strconv.Atoi(m[3])
+		}
+		if h > 23 || min > 59 {
+			return tz, fmt.Errorf("invalid timezone: %q", s)
+		}
+		tz = TZ(h*60 + min)
+		if m[1] == "-" {
+			tz = -tz
+		}
+	} else if loc, err := time.LoadLocation(s); s != "" && err == nil {
+		tz = TimeToTZ(time.Now().In(loc))
+	} else {
+		return tz, fmt.Errorf("invalid timezone: %q", s)
+	}
+
+	if !tz.Valid() {
+		return tz, fmt.Errorf("invalid timezone: %q", s)
+	}
+	return tz, nil
+}
+
+// Valid reports whether tz is a multiple of 15 minutes between
+// -16:00 and +15:15.
+func (tz TZ) Valid() bool {
+	return tz%15 == 0 && tz >= -16*60 && tz <= 15*60+15
+}
+
+// Location returns time.Local if tz is the same as the current local offset,
+// otherwise returns a fixed zone location.
+func (tz TZ) Location() *time.Location {
+	if _, local := time.Now().Zone(); int(tz)*60 == local {
+		return time.Local
+	}
+	return time.FixedZone("", int(tz)*60)
+}
+
+// String returns time zone offset formatted like ``+07:00''.
+func (tz TZ) String() string {
+	sign := '+'
+	n := int(tz)
+	if n < 0 {
+		sign = '-'
+		n = -n
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, n/60, n%60)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (tz TZ) MarshalText() ([]byte, error) {
+	return []byte(tz.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// All ParseTZ formats are accepted.
+func (tz *TZ) UnmarshalText(b []byte) (err error) {
+	*tz, err = ParseTZ(string(b))
+	return
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// The offset is encoded to 1 byte, see the documentation on the TZ type.
+func (tz TZ) MarshalBinary() ([]byte, error) {
+	b, err := tz.bits()
+	if err != nil {
+		return nil, err
+	}
+	return []byte{b}, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// See the documentation on the TZ type for more details.
+func (tz *TZ) UnmarshalBinary(b []byte) error {
+	if len(b) != 1 {
+		return fmt.Errorf("invalid length: %d", len(b))
+	}
+	if b[0]&0x80 != 0 {
+		return fmt.Errorf("invalid timezone bits: %08b", b[0])
+	}
+
+	z, err := tzFromBits(b[0])
+	if err != nil {
+		return err
+	}
+	*tz = z
+	return nil
+}
+
+// bits returns the 7 bits time zone component.
+func (tz TZ) bits() (byte, error) {
+	if !tz.Valid() {
+		return 0, fmt.Errorf("invalid timezone: %s", tz)
+	}
+	return byte(tz/15 + 64), nil
+}
+
+// tzFromBits decodes the 7 bits time zone component.
+func tzFromBits(z byte) (TZ, error) {
+	n := (int(z&0x7F) - 64) * 15
+	if z&0x7F > 125 {
+		return 0, fmt.Errorf("invalid timezone: %02d:%02d", n/60, n%60)
+	}
+	return TZ(n), nil
+}
@@ -0,0 +1,110 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TZ", func() {
+	DescribeTable("ParseTZ valid",
+		func(s string, x TZ) {
+			tz, err := ParseTZ(s)
+			Expect(err).To(Succeed())
+			Expect(tz).To(Equal(x))
+		},
+		Entry("Z", "Z", TZ(0)),
+		Entry("hour", "+07", TZ(420)),
+		Entry("hour minute", "+0545", TZ(345)),
+		Entry("colon", "-09:30", TZ(-570)),
+		Entry("max", "+15:15", TZ(915)),
+		Entry("min", "-16:00", TZ(-960)),
+		Entry("UTC", "UTC", TZ(0)),
+		Entry("IANA", "Asia/Jakarta", TZ(420)),
+	)
+
+	DescribeTable("ParseTZ invalid",
+		func(s string) {
+			_, err := ParseTZ(s)
+			Expect(err).To(MatchError("invalid timezone: " + `"` + s + `"`))
+		},
+		Entry("empty", ""),
+		Entry("no sign", "07:00"),
+		Entry("hour", "+24:00"),
+		Entry("minute", "+07:60"),
+		Entry("quarter", "+07:10"),
+		Entry("range", "+15:30"),
+		Entry("name", "Asia/Nowhere"),
+	)
+
+	DescribeTable("String",
+		func(tz TZ, x string) {
+			Expect(tz.String()).To(Equal(x))
+		},
+		Entry("UTC", TZ(0), "+00:00"),
+		Entry("plus", TZ(345), "+05:45"),
+		Entry("minus", TZ(-570), "-09:30"),
+	)
+
+	DescribeTable("Binary",
+		func(tz TZ, x string) {
+			b, err := tz.MarshalBinary()
+			Expect(err).To(Succeed(), "encode err")
+			Expect(FancyHex(b)).To(Equal(x), "encode")
+
+			var nz TZ
+			Expect(nz.UnmarshalBinary(b)).To(Succeed(), "decode err")
+			Expect(nz).To(Equal(tz), "decode")
+		},
+		Entry("UTC", TZ(0), "4."),
+		Entry("Nepal", TZ(345), "57"),
+		Entry("min", TZ(-960), ".."),
+		Entry("max", TZ(915), "7d"),
+	)
+
+	Describe("MarshalBinary", func() {
+		It("should reject non quarter hour", func() {
+			_, err := TZ(353).MarshalBinary()
+			Expect(err).To(MatchError("invalid timezone: +05:53"))
+		})
+	})
+
+	DescribeTable("UnmarshalBinary invalid",
+		func(err string, b []byte) {
+			var tz TZ
+			Expect(tz.UnmarshalBinary(b)).To(MatchError(err))
+		},
+		Entry("length", "invalid length: 2", []byte{0x40, 0x40}),
+		Entry("bits", "invalid timezone bits: 11000000", []byte{0xC0}),
+		Entry("range", "invalid timezone: 15:30", []byte{0x7E}),
+	)
+
+	Describe("JSON", func() {
+		It("should be encoded and decoded", func() {
+			b, err := json.Marshal(TZ(-570))
+			Expect(err).To(Succeed(), "encode err")
+			Expect(string(b)).To(Equal(`"-09:30"`), "encode")
+
+			var tz TZ
+			Expect(json.Unmarshal(b, &tz)).To(Succeed(), "decode err")
+			Expect(tz).To(Equal(TZ(-570)), "decode")
+		})
+	})
+
+	Describe("Location", func() {
+		It("should return local", func() {
+			Expect(TimeToTZ(time.Now()).Location()).To(Equal(time.Local))
+		})
+
+		It("should return fixed zone", func() {
+			_, local := time.Now().Zone()
+			tz := TZ(local/60 + 60)
+			t := time.Date(2021, time.February, 1, 0, 0, 0, 0, tz.Location())
+			Expect(TimeToTZ(t)).To(Equal(tz))
+		})
+	})
+})
@@ -18,33 +18,24 @@ type JsonEnc struct {
 }
 
 // String returns JSON formatted content of V field.
-// It will panic on json.Marshal error.
+// On json.Marshal error, it returns the error like
+// ``!(json: unsupported value: NaN)''.
 func (e JsonEnc) String() string {
 	b, err := json.Marshal(e.V)
 	if err != nil {
-		panic(err.Error())
+		return encError(err)
 	}
 	return string(b)
 }
 
 // IsAscii checks whether all b contents are ASCII printable characters.
 func IsAscii(b []byte) bool {
-	for _, c := range b {
-		if c < 0x20 || c > 0x7E {
-			return false
-		}
-	}
-	return true
+	return IndexNonAscii(b) < 0
 }
 
 // IsHex checks whether all b contents are upper case hexadecimal numbers.
 func IsHex(b []byte) bool {
-	for _, c := range b {
-		if !((c >= '0' && c <= '9') || (c >= 'A' && c <= 'F')) {
-			return false
-		}
-	}
-	return true
+	return IndexNonHex(b, HEX_UPPER) < 0
 }
 
 // BitString returns bits formatted content of b bytes,
@@ -164,7 +155,8 @@ func Comma(s string, idxs []int) string {
 	return b.String()
 }
 
-// Round x number to d decimal points.
+// Round x number to d decimal points. The binary value of x is rounded, so
+// 1.005 may round down, use Decimal for exact decimal rounding.
 func Round(x float64, d int) float64 {
 	return math.Round(x*math.Pow10(d)) / math.Pow10(d)
 }
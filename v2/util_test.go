@@ -34,10 +34,9 @@ var _ = Describe("Json Enc", func() {
 
 	Describe("String", func() {
 		Context("error", func() {
-			It("should panic", func() {
-				Expect(func() {
-					_ = JsonEnc{math.NaN()}.String()
-				}).Should(Panic())
+			It("should return the error", func() {
+				Expect(JsonEnc{math.NaN()}.String()).To(
+					Equal("!(json: unsupported value: NaN)"))
 			})
 		})
 	})
@@ -214,6 +213,9 @@ var _ = DescribeTable("Round",
 	Entry("half 1", 1.25, 1, 1.3),
 	Entry("lower 2", 1.654, 2, 1.65),
 	Entry("half 2", 1.225, 2, 1.23),
+	Entry("negative", -2.5, 0, -3.0),
+	Entry("tens", 1250.0, -2, 1300.0),
+	Entry("infinity", math.Inf(1), 2, math.Inf(1)),
 )
 
 var _ = DescribeTable("TruncDate",
@@ -1,13 +1,21 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strconv"
+
+	"github.com/fxamacker/cbor/v2"
 )
 
 // Version represents semantic versioning data.
 // This struct has CBOR ``toarray'' struct tags.
+//
+// The binary format is 3 bytes of major, minor and patch components.
+// The extended binary format of SemVer is also accepted as long as it has no
+// pre-release identifiers and all components fit in a byte.
 type Version struct {
 	_     struct{} `cbor:",toarray"`
 	Major uint8
@@ -31,6 +39,33 @@ func (v Version) Before(o Version) bool {
 			(v.Minor == o.Minor && v.Patch < o.Patch)))
 }
 
+// BumpMajor returns the next major version, resetting minor and patch
+// components. It returns error if the major component would overflow.
+func (v Version) BumpMajor() (Version, error) {
+	if v.Major == 255 {
+		return v, fmt.Errorf("Major version overflow: %s", v)
+	}
+	return MakeVersion(v.Major+1, 0, 0), nil
+}
+
+// BumpMinor returns the next minor version, resetting patch component.
+// It returns error if the minor component would overflow.
+func (v Version) BumpMinor() (Version, error) {
+	if v.Minor == 255 {
+		return v, fmt.Errorf("Minor version overflow: %s", v)
+	}
+	return MakeVersion(v.Major, v.Minor+1, 0), nil
+}
+
+// BumpPatch returns the next patch version.
+// It returns error if the patch component would overflow.
+func (v Version) BumpPatch() (Version, error) {
+	if v.Patch == 255 {
+		return v, fmt.Errorf("Patch version overflow: %s", v)
+	}
+	return MakeVersion(v.Major, v.Minor, v.Patch+1), nil
+}
+
 // String returns a string representing the version.
 func (v Version) String() string {
 	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
@@ -72,3 +107,122 @@ func (v *Version) UnmarshalText(b []byte) error {
 
 	return nil
 }
+
+type versionObject struct {
+	Major uint8 `json:"major"`
+	Minor uint8 `json:"minor"`
+	Patch uint8 `json:"patch"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The version is encoded as JSON_STRING, use VersionJson for JSON_OBJECT.
+func (v Version) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// Both string and object JSON forms are accepted.
+func (v *Version) UnmarshalJSON(b []byte) error {
+	switch jsonKind(b) {
+	case 'n':
+		if bytes.Equal(bytes.TrimSpace(b), jsonNull) {
+			return nil
+		}
+	case '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		return v.UnmarshalText([]byte(s))
+	case '{':
+		var o versionObject
+		if err := json.Unmarshal(b, &o); err != nil {
+			return err
+		}
+		*v = MakeVersion(o.Major, o.Minor, o.Patch)
+		return nil
+	}
+	return fmt.Errorf("Invalid json for %T: %s", v, b)
+}
+
+// VersionJson wraps Version to be encoded in Form JSON form, e.g.
+//     json.Marshal(VersionJson{Version: v, Form: JSON_OBJECT})
+// JSON_EPOCH is not applicable to version so it will be encoded as
+// JSON_STRING. Both forms are decoded like Version.
+type VersionJson struct {
+	Version
+	Form JsonForm
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (j VersionJson) MarshalJSON() ([]byte, error) {
+	if j.Form == JSON_OBJECT {
+		return json.Marshal(versionObject{j.Major, j.Minor, j.Patch})
+	}
+	return j.Version.MarshalJSON()
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+// See the documentation on the Version type for more details.
+func (v Version) MarshalBinary() ([]byte, error) {
+	return []byte{v.Major, v.Minor, v.Patch}, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// See the documentation on the Version type for more details.
+func (v *Version) UnmarshalBinary(b []byte) error {
+	if len(b) == 3 {
+		*v = MakeVersion(b[0], b[1], b[2])
+		return nil
+	}
+
+	var s SemVer
+	if err := s.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	nv, err := s.Version()
+	if err != nil {
+		return err
+	}
+	*v = nv
+	return nil
+}
+
+// MarshalCBOR implements the cbor.Marshaler interface.
+// The version is encoded as array of its components, as the CBOR ``toarray''
+// struct tags.
+func (v Version) MarshalCBOR() ([]byte, error) {
+	return cbor.Marshal([]uint{uint(v.Major), uint(v.Minor), uint(v.Patch)})
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface.
+// Beside the MarshalCBOR output, the binary format byte string and text
+// format string are also accepted.
+func (v *Version) UnmarshalCBOR(b []byte) error {
+	var i interface{}
+	if err := cbor.Unmarshal(b, &i); err != nil {
+		return err
+	}
+
+	switch c := i.(type) {
+	case []interface{}:
+		if len(c) != 3 {
+			return fmt.Errorf("Invalid cbor array length: %d", len(c))
+		}
+		var n [3]uint8
+		for j, e := range c {
+			u, ok := e.(uint64)
+			if !ok || u > 255 {
+				return fmt.Errorf("Invalid cbor component: %v", e)
+			}
+			n[j] = uint8(u)
+		}
+		*v = MakeVersion(n[0], n[1], n[2])
+		return nil
+	case []byte:
+		return v.UnmarshalBinary(c)
+	case string:
+		return v.UnmarshalText([]byte(c))
+	}
+	return fmt.Errorf("Invalid cbor type for %T: %T", v, i)
+}
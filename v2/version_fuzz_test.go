@@ -0,0 +1,85 @@
+//go:build go1.18
+// +build go1.18
+
+package util_test
+
+import (
+	"bytes"
+	. "github.com/hanindo/util/v2"
+	"testing"
+)
+
+func FuzzVersionBinary(f *testing.F) {
+	f.Add([]byte{1, 2, 3})
+	f.Add([]byte{1, 2, 3, 0, 3, 'b', '.', '1'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var v Version
+		if err := v.UnmarshalBinary(b); err != nil {
+			return
+		}
+		eb, err := v.MarshalBinary()
+		if err != nil {
+			t.Fatalf("encode %s: %v", v, err)
+		}
+		var nv Version
+		if err := nv.UnmarshalBinary(eb); err != nil {
+			t.Fatalf("decode %s: %v", FancyHex(eb), err)
+		}
+		if nv != v {
+			t.Fatalf("round trip %s: %s != %s", FancyHex(b), nv, v)
+		}
+	})
+}
+
+func FuzzSemVerBinary(f *testing.F) {
+	f.Add([]byte{1, 2, 3})
+	f.Add([]byte{1, 0x80, 0x02, 3, 0, 0})
+	f.Add([]byte{1, 2, 3, 4, 'r', 'c', '.', '1', 1, 'b'})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var s SemVer
+		if err := s.UnmarshalBinary(b); err != nil {
+			return
+		}
+		eb, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("encode %s: %v", s, err)
+		}
+		var ns SemVer
+		if err := ns.UnmarshalBinary(eb); err != nil {
+			t.Fatalf("decode %s: %v", FancyHex(eb), err)
+		}
+		if ns.String() != s.String() {
+			t.Fatalf("round trip %s: %s != %s", FancyHex(b), ns, s)
+		}
+		// The encoded form must be canonical.
+		if nb, _ := ns.MarshalBinary(); !bytes.Equal(nb, eb) {
+			t.Fatalf("encode %s: %s != %s", s, FancyHex(nb), FancyHex(eb))
+		}
+	})
+}
+
+func FuzzSemVerText(f *testing.F) {
+	f.Add("1.2.3")
+	f.Add("1.4.0-rc.1+build.7")
+	f.Add("1.0.0-x-y-z.--")
+	f.Fuzz(func(t *testing.T, str string) {
+		s, err := ParseSemVer(str)
+		if err != nil {
+			return
+		}
+		if s.String() != str {
+			t.Fatalf("text round trip: %q != %q", s.String(), str)
+		}
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("encode %s: %v", s, err)
+		}
+		var ns SemVer
+		if err := ns.UnmarshalBinary(b); err != nil {
+			t.Fatalf("decode %s: %v", FancyHex(b), err)
+		}
+		if ns.String() != str {
+			t.Fatalf("binary round trip: %q != %q", ns.String(), str)
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	. "github.com/hanindo/util/v2"
 
+	"github.com/fxamacker/cbor/v2"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -58,6 +60,101 @@ var _ = Describe("Version", func() {
 		})
 	})
 
+	Describe("JSON object", func() {
+		It("should be encoded", func() {
+			v = MakeVersion(1, 2, 3)
+			Expect(json.Marshal(VersionJson{Version: v, Form: JSON_OBJECT})).
+				To(Equal([]byte(`{"major":1,"minor":2,"patch":3}`)))
+			Expect(json.Marshal(VersionJson{Version: v, Form: JSON_EPOCH})).
+				To(Equal([]byte(`"1.2.3"`)))
+		})
+
+		It("should be decoded by wrapper", func() {
+			var j VersionJson
+			b := []byte(`{"major":4,"minor":5,"patch":6}`)
+			Expect(json.Unmarshal(b, &j)).To(Succeed())
+			Expect(j.Version).To(Equal(MakeVersion(4, 5, 6)))
+		})
+
+		It("should be decoded", func() {
+			b := []byte(`{"major":4,"minor":5,"patch":6}`)
+			Expect(json.Unmarshal(b, &v)).To(Succeed())
+			Expect(v).To(Equal(MakeVersion(4, 5, 6)))
+		})
+
+		It("should not decode overflow", func() {
+			b := []byte(`{"major":256,"minor":5,"patch":6}`)
+			Expect(json.Unmarshal(b, &v)).To(HaveOccurred())
+		})
+	})
+
+	Describe("CBOR", func() {
+		It("should be encoded as array", func() {
+			b, err := cbor.Marshal(MakeVersion(1, 2, 255))
+			Expect(err).To(Succeed(), "encode err")
+			Expect(FancyHex(b)).To(Equal("83 .1 .2 18 ff"), "encode")
+
+			var nv Version
+			Expect(cbor.Unmarshal(b, &nv)).To(Succeed(), "decode err")
+			Expect(nv).To(Equal(MakeVersion(1, 2, 255)), "decode")
+		})
+
+		DescribeTable("decode",
+			func(b []byte) {
+				var nv Version
+				Expect(cbor.Unmarshal(b, &nv)).To(Succeed())
+				Expect(nv).To(Equal(MakeVersion(1, 2, 3)))
+			},
+			Entry("binary", []byte{0x43, 0x01, 0x02, 0x03}),
+			Entry("text", []byte{0x65, '1', '.', '2', '.', '3'}),
+		)
+
+		DescribeTable("invalid",
+			func(err string, b []byte) {
+				var nv Version
+				Expect(cbor.Unmarshal(b, &nv)).To(MatchError(err))
+			},
+			Entry("length", "Invalid cbor array length: 2",
+				[]byte{0x82, 0x01, 0x02}),
+			Entry("component", "Invalid cbor component: 256",
+				[]byte{0x83, 0x01, 0x02, 0x19, 0x01, 0x00}),
+			Entry("type", "Invalid cbor type for *util.Version: uint64",
+				[]byte{0x01}),
+		)
+	})
+
+	Describe("Binary", func() {
+		It("should be encoded in 3 bytes", func() {
+			b, err := MakeVersion(1, 2, 255).MarshalBinary()
+			Expect(err).To(Succeed(), "encode err")
+			Expect(FancyHex(b)).To(Equal(".1 .2 ff"), "encode")
+
+			var nv Version
+			Expect(nv.UnmarshalBinary(b)).To(Succeed(), "decode err")
+			Expect(nv).To(Equal(MakeVersion(1, 2, 255)), "decode")
+		})
+
+		It("should decode extended format", func() {
+			var nv Version
+			Expect(nv.UnmarshalBinary([]byte{1, 2, 3, 0, 3, 'b', '.', '1'})).
+				To(Succeed())
+			Expect(nv).To(Equal(MakeVersion(1, 2, 3)))
+		})
+
+		DescribeTable("invalid",
+			func(err string, b []byte) {
+				var nv Version
+				Expect(nv.UnmarshalBinary(b)).To(MatchError(err))
+			},
+			Entry("short", "invalid length: 2", []byte{0x01, 0x02}),
+			Entry("long", "invalid length: 4", []byte{1, 2, 3, 4}),
+			Entry("wide", "Component outside range 0-255: 1.256.3",
+				[]byte{0x01, 0x80, 0x02, 0x03, 0x00, 0x00}),
+			Entry("pre-release", "Unsupported pre-release: 1.2.3-rc",
+				[]byte{1, 2, 3, 2, 'r', 'c', 0}),
+		)
+	})
+
 	Describe("JSON decode", func() {
 		DescribeTable("valid",
 			func(s string, x Version) {
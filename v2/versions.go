@@ -0,0 +1,88 @@
+package util
+
+import (
+	"sort"
+)
+
+// Versions is a list of versions, it implements sort.Interface to sort the
+// versions in ascending order.
+type Versions []Version
+
+func (vs Versions) Len() int           { return len(vs) }
+func (vs Versions) Less(i, j int) bool { return vs[i].Before(vs[j]) }
+func (vs Versions) Swap(i, j int)      { vs[i], vs[j] = vs[j], vs[i] }
+
+// Sort sorts vs in ascending order and returns it for convenience.
+func (vs Versions) Sort() Versions {
+	sort.Sort(vs)
+	return vs
+}
+
+// Max returns the highest version, it returns false if vs is empty.
+func (vs Versions) Max() (max Version, ok bool) {
+	for _, v := range vs {
+		if !ok || max.Before(v) {
+			max, ok = v, true
+		}
+	}
+	return
+}
+
+// Min returns the lowest version, it returns false if vs is empty.
+func (vs Versions) Min() (min Version, ok bool) {
+	for _, v := range vs {
+		if !ok || v.Before(min) {
+			min, ok = v, true
+		}
+	}
+	return
+}
+
+// Contains reports whether v is in vs.
+func (vs Versions) Contains(v Version) bool {
+	for _, e := range vs {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Dedupe returns a new list without duplicate versions,
+// keeping the first occurrence order.
+func (vs Versions) Dedupe() Versions {
+	seen := make(map[Version]bool, len(vs))
+	r := make(Versions, 0, len(vs))
+	for _, v := range vs {
+		if !seen[v] {
+			seen[v] = true
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+// GroupByMajor returns the versions grouped by their major component,
+// the order of versions in each group follows vs.
+func (vs Versions) GroupByMajor() map[uint8]Versions {
+	m := make(map[uint8]Versions)
+	for _, v := range vs {
+		m[v.Major] = append(m[v.Major], v)
+	}
+	return m
+}
+
+// Majors returns the distinct major components in ascending order.
+func (vs Versions) Majors() []uint8 {
+	var seen [256]bool
+	var r []uint8
+	for _, v := range vs {
+		seen[v.Major] = true
+	}
+	for i, ok := range seen {
+		if ok {
+			r = append(r, uint8(i))
+		}
+	}
+	return r
+}
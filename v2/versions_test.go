@@ -0,0 +1,88 @@
+package util_test
+
+import (
+	. "github.com/hanindo/util/v2"
+	"sort"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Versions", func() {
+	list := func(s string) Versions {
+		vs, err := ParseVersionList(s)
+		Expect(err).To(Succeed(), s)
+		return vs
+	}
+
+	DescribeTable("Bump",
+		func(v Version, major, minor, patch string) {
+			for i, f := range []func() (Version, error){
+				v.BumpMajor, v.BumpMinor, v.BumpPatch,
+			} {
+				x := []string{major, minor, patch}[i]
+				b, err := f()
+				if x == "" {
+					Expect(err).To(HaveOccurred())
+					Expect(b).To(Equal(v))
+				} else {
+					Expect(err).To(Succeed())
+					Expect(b.String()).To(Equal(x))
+				}
+			}
+		},
+		Entry("zero", MakeVersion(0, 0, 0), "1.0.0", "0.1.0", "0.0.1"),
+		Entry("reset", MakeVersion(1, 2, 3), "2.0.0", "1.3.0", "1.2.4"),
+		Entry("overflow major", MakeVersion(255, 2, 3), "", "255.3.0",
+			"255.2.4"),
+		Entry("overflow minor", MakeVersion(1, 255, 3), "2.0.0", "",
+			"1.255.4"),
+		Entry("overflow patch", MakeVersion(1, 2, 255), "2.0.0", "1.3.0", ""),
+	)
+
+	It("should report overflow", func() {
+		_, err := MakeVersion(1, 255, 0).BumpMinor()
+		Expect(err).To(MatchError("Minor version overflow: 1.255.0"))
+	})
+
+	It("should sort", func() {
+		vs := list("1.10.0 1.2.0 0.9.9 2.0.0 1.2.0 1.2.1")
+		Expect(sort.IsSorted(vs)).To(BeFalse())
+		Expect(vs.Sort()).To(Equal(list("0.9.9 1.2.0 1.2.0 1.2.1 1.10.0 2.0.0")))
+		Expect(sort.IsSorted(vs)).To(BeTrue())
+	})
+
+	It("should find max and min", func() {
+		vs := list("1.10.0 1.2.0 0.9.9 2.0.0")
+		v, ok := vs.Max()
+		Expect(v).To(Equal(MakeVersion(2, 0, 0)))
+		Expect(ok).To(BeTrue())
+		v, ok = vs.Min()
+		Expect(v).To(Equal(MakeVersion(0, 9, 9)))
+		Expect(ok).To(BeTrue())
+
+		_, ok = Versions{}.Max()
+		Expect(ok).To(BeFalse())
+		_, ok = Versions(nil).Min()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should dedupe", func() {
+		vs := list("1.2.0 1.0.0 1.2.0 2.0.0 1.0.0")
+		Expect(vs.Dedupe()).To(Equal(list("1.2.0 1.0.0 2.0.0")))
+		Expect(vs).To(HaveLen(5))
+		Expect(vs.Contains(MakeVersion(2, 0, 0))).To(BeTrue())
+		Expect(vs.Contains(MakeVersion(2, 0, 1))).To(BeFalse())
+	})
+
+	It("should group by major", func() {
+		vs := list("1.2.0 0.1.0 1.0.0 3.0.0 1.5.0")
+		Expect(vs.GroupByMajor()).To(Equal(map[uint8]Versions{
+			0: list("0.1.0"),
+			1: list("1.2.0 1.0.0 1.5.0"),
+			3: list("3.0.0"),
+		}))
+		Expect(vs.Majors()).To(Equal([]uint8{0, 1, 3}))
+	})
+})
@@ -0,0 +1,166 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// YearMonth represents a month of a year with timezone.
+//
+// The binary format is 4 bytes with ``0b1100'' type tag, see period type
+// tags documentation for details.
+type YearMonth struct {
+	year  int
+	month time.Month
+	tz    TZ
+}
+
+// MakeYearMonth returns the month of d date.
+func MakeYearMonth(d Date) YearMonth {
+	y, m, _ := d.tm.Date()
+	return YearMonth{year: y, month: m, tz: d.TZ()}
+}
+
+// Year returns the year of ym.
+func (ym YearMonth) Year() int {
+	return ym.year
+}
+
+// Month returns the month of ym.
+func (ym YearMonth) Month() time.Month {
+	return ym.month
+}
+
+// TZ returns the time zone offset of ym.
+func (ym YearMonth) TZ() TZ {
+	return ym.tz
+}
+
+// YearQuarter returns the quarter containing ym.
+func (ym YearMonth) YearQuarter() YearQuarter {
+	return YearQuarter{
+		year:    ym.year,
+		quarter: int(ym.month+2) / 3,
+		tz:      ym.tz,
+	}
+}
+
+// First returns the first date of ym.
+func (ym YearMonth) First() Date {
+	return makeDay(ym.year, ym.month, 1, ym.tz)
+}
+
+// Last returns the last date of ym.
+func (ym YearMonth) Last() Date {
+	return makeDay(ym.year, ym.month+1, 0, ym.tz)
+}
+
+// Dates returns all dates of ym.
+func (ym YearMonth) Dates() []Date {
+	return datesBetween(ym.First(), ym.Last())
+}
+
+// Contains reports whether d date is in ym, the time zone is ignored.
+func (ym YearMonth) Contains(d Date) bool {
+	y, m, _ := d.tm.Date()
+	return y == ym.year && m == ym.month
+}
+
+// AddMonths returns ym plus n months.
+func (ym YearMonth) AddMonths(n int) YearMonth {
+	y, m := divPeriod(ym.index()+n, 12)
+	return YearMonth{year: y, month: time.Month(m), tz: ym.tz}
+}
+
+// Sub returns the number of months from o to ym.
+func (ym YearMonth) Sub(o YearMonth) int {
+	return ym.index() - o.index()
+}
+
+func (ym YearMonth) index() int {
+	return ym.year*12 + int(ym.month) - 1
+}
+
+// Next returns the month after ym.
+func (ym YearMonth) Next() YearMonth {
+	return ym.AddMonths(1)
+}
+
+// Prev returns the month before ym.
+func (ym YearMonth) Prev() YearMonth {
+	return ym.AddMonths(-1)
+}
+
+// Compare returns -1, 0 or +1 if ym is before, same or after o.
+// The time zone is ignored.
+func (ym YearMonth) Compare(o YearMonth) int {
+	return comparePeriod(ym.year, int(ym.month), o.year, int(o.month))
+}
+
+// Before reports whether ym is before o, the time zone is ignored.
+func (ym YearMonth) Before(o YearMonth) bool {
+	return ym.Compare(o) < 0
+}
+
+// After reports whether ym is after o, the time zone is ignored.
+func (ym YearMonth) After(o YearMonth) bool {
+	return ym.Compare(o) > 0
+}
+
+// Equal reports whether ym and o represent the same month in the same
+// time zone.
+func (ym YearMonth) Equal(o YearMonth) bool {
+	return ym == o
+}
+
+// String returns month formatted like ``2021-02 +07:00''.
+func (ym YearMonth) String() string {
+	return fmt.Sprintf("%04d-%02d %s", ym.year, ym.month, ym.tz)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (ym YearMonth) MarshalText() ([]byte, error) {
+	return []byte(ym.String()), nil
+}
+
+var yearMonthRE = regexp.MustCompile(`^(\d{4})-(0[1-9]|1[0-2]) ([+-]\d\d:\d\d)$`)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The month is expected exactly like String() format.
+func (ym *YearMonth) UnmarshalText(b []byte) error {
+	m := yearMonthRE.FindSubmatch(b)
+	if m == nil {
+		return fmt.Errorf("invalid text for %T: %q", ym, b)
+	}
+
+	tz, err := ParseTZ(string(m[3]))
+	if err != nil {
+		return err
+	}
+	y, _ := strconv.Atoi(string(m[1]))
+	mo, _ := strconv.Atoi(string(m[2]))
+	*ym = YearMonth{year: y, month: time.Month(mo), tz: tz}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (ym YearMonth) MarshalBinary() ([]byte, error) {
+	return marshalPeriod(year_month_tag, ym.year, "month", int(ym.month), 12,
+		ym.tz)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (ym *YearMonth) UnmarshalBinary(b []byte) error {
+	y, m, tz, err := unmarshalPeriod(year_month_tag, b)
+	if err != nil {
+		return err
+	}
+	if m > 12 {
+		return fmt.Errorf("invalid month: %d", m)
+	}
+	*ym = YearMonth{year: y, month: time.Month(m), tz: tz}
+	return nil
+}
@@ -0,0 +1,171 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// YearQuarter represents a quarter of a year with timezone.
+//
+// The binary format is 4 bytes with ``0b1101'' type tag, see period type
+// tags documentation for details.
+type YearQuarter struct {
+	year    int
+	quarter int
+	tz      TZ
+}
+
+// MakeYearQuarter returns the quarter of d date.
+func MakeYearQuarter(d Date) YearQuarter {
+	return MakeYearMonth(d).YearQuarter()
+}
+
+// Year returns the year of yq.
+func (yq YearQuarter) Year() int {
+	return yq.year
+}
+
+// Quarter returns the quarter of yq, from 1 to 4.
+func (yq YearQuarter) Quarter() int {
+	return yq.quarter
+}
+
+// TZ returns the time zone offset of yq.
+func (yq YearQuarter) TZ() TZ {
+	return yq.tz
+}
+
+// FirstMonth returns the first month of yq.
+func (yq YearQuarter) FirstMonth() YearMonth {
+	return YearMonth{
+		year:  yq.year,
+		month: time.Month(yq.quarter*3 - 2),
+		tz:    yq.tz,
+	}
+}
+
+// Months returns all months of yq.
+func (yq YearQuarter) Months() []YearMonth {
+	m := yq.FirstMonth()
+	return []YearMonth{m, m.Next(), m.AddMonths(2)}
+}
+
+// First returns the first date of yq.
+func (yq YearQuarter) First() Date {
+	return yq.FirstMonth().First()
+}
+
+// Last returns the last date of yq.
+func (yq YearQuarter) Last() Date {
+	return yq.FirstMonth().AddMonths(2).Last()
+}
+
+// Dates returns all dates of yq.
+func (yq YearQuarter) Dates() []Date {
+	return datesBetween(yq.First(), yq.Last())
+}
+
+// Contains reports whether d date is in yq, the time zone is ignored.
+func (yq YearQuarter) Contains(d Date) bool {
+	o := MakeYearQuarter(d)
+	return o.year == yq.year && o.quarter == yq.quarter
+}
+
+// AddQuarters returns yq plus n quarters.
+func (yq YearQuarter) AddQuarters(n int) YearQuarter {
+	y, q := divPeriod(yq.index()+n, 4)
+	return YearQuarter{year: y, quarter: q, tz: yq.tz}
+}
+
+// Sub returns the number of quarters from o to yq.
+func (yq YearQuarter) Sub(o YearQuarter) int {
+	return yq.index() - o.index()
+}
+
+func (yq YearQuarter) index() int {
+	return yq.year*4 + yq.quarter - 1
+}
+
+// Next returns the quarter after yq.
+func (yq YearQuarter) Next() YearQuarter {
+	return yq.AddQuarters(1)
+}
+
+// Prev returns the quarter before yq.
+func (yq YearQuarter) Prev() YearQuarter {
+	return yq.AddQuarters(-1)
+}
+
+// Compare returns -1, 0 or +1 if yq is before, same or after o.
+// The time zone is ignored.
+func (yq YearQuarter) Compare(o YearQuarter) int {
+	return comparePeriod(yq.year, yq.quarter, o.year, o.quarter)
+}
+
+// Before reports whether yq is before o, the time zone is ignored.
+func (yq YearQuarter) Before(o YearQuarter) bool {
+	return yq.Compare(o) < 0
+}
+
+// After reports whether yq is after o, the time zone is ignored.
+func (yq YearQuarter) After(o YearQuarter) bool {
+	return yq.Compare(o) > 0
+}
+
+// Equal reports whether yq and o represent the same quarter in the same
+// time zone.
+func (yq YearQuarter) Equal(o YearQuarter) bool {
+	return yq == o
+}
+
+// String returns quarter formatted like ``2021-Q1 +07:00''.
+func (yq YearQuarter) String() string {
+	return fmt.Sprintf("%04d-Q%d %s", yq.year, yq.quarter, yq.tz)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (yq YearQuarter) MarshalText() ([]byte, error) {
+	return []byte(yq.String()), nil
+}
+
+var yearQuarterRE = regexp.MustCompile(`^(\d{4})-Q([1-4]) ([+-]\d\d:\d\d)$`)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The quarter is expected exactly like String() format.
+func (yq *YearQuarter) UnmarshalText(b []byte) error {
+	m := yearQuarterRE.FindSubmatch(b)
+	if m == nil {
+		return fmt.Errorf("invalid text for %T: %q", yq, b)
+	}
+
+	tz, err := ParseTZ(string(m[3]))
+	if err != nil {
+		return err
+	}
+	y, _ := strconv.Atoi(string(m[1]))
+	q, _ := strconv.Atoi(string(m[2]))
+	*yq = YearQuarter{year: y, quarter: q, tz: tz}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (yq YearQuarter) MarshalBinary() ([]byte, error) {
+	return marshalPeriod(year_quarter_tag, yq.year, "quarter", yq.quarter, 4,
+		yq.tz)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (yq *YearQuarter) UnmarshalBinary(b []byte) error {
+	y, q, tz, err := unmarshalPeriod(year_quarter_tag, b)
+	if err != nil {
+		return err
+	}
+	if q > 4 {
+		return fmt.Errorf("invalid quarter: %d", q)
+	}
+	*yq = YearQuarter{year: y, quarter: q, tz: tz}
+	return nil
+}
@@ -0,0 +1,178 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// YearWeek represents an ISO 8601 week of a year with timezone.
+// The week starts on Monday and the first week of the year is the week
+// containing the first Thursday, so the year may differ from the calendar
+// year of its dates.
+//
+// The binary format is 4 bytes with ``0b1110'' type tag, see period type
+// tags documentation for details.
+type YearWeek struct {
+	year int
+	week int
+	tz   TZ
+}
+
+// MakeYearWeek returns the ISO week of d date.
+func MakeYearWeek(d Date) YearWeek {
+	y, w := d.tm.ISOWeek()
+	return YearWeek{year: y, week: w, tz: d.TZ()}
+}
+
+// WeeksInYear returns the number of ISO weeks in y year, 52 or 53.
+func WeeksInYear(y int) int {
+	_, w := time.Date(y, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return w
+}
+
+// Year returns the ISO year of yw.
+func (yw YearWeek) Year() int {
+	return yw.year
+}
+
+// Week returns the ISO week of yw, from 1 to 53.
+func (yw YearWeek) Week() int {
+	return yw.week
+}
+
+// TZ returns the time zone offset of yw.
+func (yw YearWeek) TZ() TZ {
+	return yw.tz
+}
+
+// monday returns the first day of yw as days offset from 4 January, which is
+// always in the first week.
+func (yw YearWeek) monday() int {
+	jan4 := time.Date(yw.year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	return -(int(jan4.Weekday())+6)%7 + (yw.week-1)*7
+}
+
+// First returns the Monday of yw.
+func (yw YearWeek) First() Date {
+	return makeDay(yw.year, time.January, 4+yw.monday(), yw.tz)
+}
+
+// Last returns the Sunday of yw.
+func (yw YearWeek) Last() Date {
+	return makeDay(yw.year, time.January, 4+yw.monday()+6, yw.tz)
+}
+
+// Dates returns all dates of yw.
+func (yw YearWeek) Dates() []Date {
+	return datesBetween(yw.First(), yw.Last())
+}
+
+// Contains reports whether d date is in yw, the time zone is ignored.
+func (yw YearWeek) Contains(d Date) bool {
+	y, w := d.tm.ISOWeek()
+	return y == yw.year && w == yw.week
+}
+
+// AddWeeks returns yw plus n weeks.
+func (yw YearWeek) AddWeeks(n int) YearWeek {
+	t := time.Date(yw.year, time.January, 4+yw.monday()+n*7, 0, 0, 0, 0,
+		time.UTC)
+	y, w := t.ISOWeek()
+	return YearWeek{year: y, week: w, tz: yw.tz}
+}
+
+// Sub returns the number of weeks from o to yw.
+func (yw YearWeek) Sub(o YearWeek) int {
+	t1 := time.Date(yw.year, time.January, 4+yw.monday(), 0, 0, 0, 0,
+		time.UTC)
+	t2 := time.Date(o.year, time.January, 4+o.monday(), 0, 0, 0, 0,
+		time.UTC)
+	return int(t1.Sub(t2) / (7 * 24 * time.Hour))
+}
+
+// Next returns the week after yw.
+func (yw YearWeek) Next() YearWeek {
+	return yw.AddWeeks(1)
+}
+
+// Prev returns the week before yw.
+func (yw YearWeek) Prev() YearWeek {
+	return yw.AddWeeks(-1)
+}
+
+// Compare returns -1, 0 or +1 if yw is before, same or after o.
+// The time zone is ignored.
+func (yw YearWeek) Compare(o YearWeek) int {
+	return comparePeriod(yw.year, yw.week, o.year, o.week)
+}
+
+// Before reports whether yw is before o, the time zone is ignored.
+func (yw YearWeek) Before(o YearWeek) bool {
+	return yw.Compare(o) < 0
+}
+
+// After reports whether yw is after o, the time zone is ignored.
+func (yw YearWeek) After(o YearWeek) bool {
+	return yw.Compare(o) > 0
+}
+
+// Equal reports whether yw and o represent the same week in the same
+// time zone.
+func (yw YearWeek) Equal(o YearWeek) bool {
+	return yw == o
+}
+
+// String returns week formatted like ``2021-W05 +07:00''.
+func (yw YearWeek) String() string {
+	return fmt.Sprintf("%04d-W%02d %s", yw.year, yw.week, yw.tz)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (yw YearWeek) MarshalText() ([]byte, error) {
+	return []byte(yw.String()), nil
+}
+
+var yearWeekRE = regexp.MustCompile(`^(\d{4})-W(\d\d) ([+-]\d\d:\d\d)$`)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The week is expected exactly like String() format.
+func (yw *YearWeek) UnmarshalText(b []byte) error {
+	m := yearWeekRE.FindSubmatch(b)
+	if m == nil {
+		return fmt.Errorf("invalid text for %T: %q", yw, b)
+	}
+
+	tz, err := ParseTZ(string(m[3]))
+	if err != nil {
+		return err
+	}
+	y, _ := strconv.Atoi(string(m[1]))
+	w, _ := strconv.Atoi(string(m[2]))
+	if w < 1 || w > WeeksInYear(y) {
+		return fmt.Errorf("invalid week: %04d-W%02d", y, w)
+	}
+	*yw = YearWeek{year: y, week: w, tz: tz}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (yw YearWeek) MarshalBinary() ([]byte, error) {
+	return marshalPeriod(year_week_tag, yw.year, "week", yw.week,
+		WeeksInYear(yw.year), yw.tz)
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (yw *YearWeek) UnmarshalBinary(b []byte) error {
+	y, w, tz, err := unmarshalPeriod(year_week_tag, b)
+	if err != nil {
+		return err
+	}
+	if w > WeeksInYear(y) {
+		return fmt.Errorf("invalid week: %04d-W%02d", y, w)
+	}
+	*yw = YearWeek{year: y, week: w, tz: tz}
+	return nil
+}
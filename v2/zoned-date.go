@@ -0,0 +1,227 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ZonedDate represents a date in an IANA time zone such as ``Asia/Jakarta''.
+//
+// Unlike Date which only keeps the time zone offset, ZonedDate keeps the
+// time zone location so the day boundaries are always computed using the
+// time zone database, including days that don't start at 00:00 because of
+// daylight saving time transition.
+//
+// The text format is RFC 9557 style date with time zone suffix, e.g.
+//     "2021-02-01[Asia/Jakarta]"
+// Location without IANA name, such as time.FixedZone("") or
+// time.FixedZone("WIB"), uses offset suffix like
+//     "2021-02-01[+07:00]"
+// The time.Local location is named by the TZ environment variable or the
+// /etc/localtime link, like ``Asia/Jakarta'', or by its offset if its IANA
+// name is unknown.
+type ZonedDate struct {
+	tm time.Time
+}
+
+// MakeZonedDate returns a zoned date from t time and its location.
+func MakeZonedDate(t time.Time) ZonedDate {
+	y, m, d := t.Date()
+	tm, _ := startOfDay(y, m, d, t.Location())
+	return ZonedDate{tm: tm}
+}
+
+// startOfDay returns the first instant of the date in loc location.
+// It returns false if the date doesn't exist in that location.
+func startOfDay(y int, m time.Month, d int, loc *time.Location) (
+	time.Time, bool,
+) {
+	t := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	if dateCmp(t, y, m, d) == 0 && dateCmp(t.Add(-time.Second), y, m, d) < 0 {
+		return t, true
+	}
+
+	// Midnight doesn't exist or is repeated, search the first instant
+	// between the previous day and the noon.
+	lo := t.Add(-24 * time.Hour).Unix()
+	hi := time.Date(y, m, d, 12, 0, 0, 0, loc)
+	if dateCmp(hi, y, m, d) != 0 {
+		return t, false
+	}
+	for hiu := hi.Unix(); hiu-lo > 1; {
+		mid := lo + (hiu-lo)/2
+		if dateCmp(time.Unix(mid, 0).In(loc), y, m, d) < 0 {
+			lo = mid
+		} else {
+			hiu = mid
+			hi = time.Unix(mid, 0).In(loc)
+		}
+	}
+	return hi, true
+}
+
+// dateCmp compares the date of t time with y-m-d date.
+func dateCmp(t time.Time, y int, m time.Month, d int) int {
+	ty, tm, td := t.Date()
+	switch {
+	case ty != y:
+		return ty - y
+	case tm != m:
+		return int(tm - m)
+	}
+	return td - d
+}
+
+// Equal reports whether z and o represent the same date in the same
+// location.
+func (z ZonedDate) Equal(o ZonedDate) bool {
+	return z.tm.Equal(o.tm) && z.Zone() == o.Zone()
+}
+
+// Time returns the starting time of date.
+func (z ZonedDate) Time() time.Time {
+	return z.tm
+}
+
+// End returns the starting time of the next date.
+func (z ZonedDate) End() time.Time {
+	return z.AddDate(0, 0, 1).tm
+}
+
+// Location returns the time zone location of date.
+func (z ZonedDate) Location() *time.Location {
+	return z.tm.Location()
+}
+
+// Zone returns the time zone name of date, or its offset if the location
+// doesn't have IANA name.
+func (z ZonedDate) Zone() string {
+	loc := z.tm.Location()
+	name := loc.String()
+	if loc == time.Local {
+		name = localZoneName()
+	} else if name != "" && !ianaZoneName(name) {
+		name = ""
+	}
+	if name != "" {
+		return name
+	}
+	return TimeToTZ(z.tm).String()
+}
+
+// ianaZones caches whether a location name is loadable by LoadLocation.
+var ianaZones sync.Map
+
+// ianaZoneName reports whether name is loadable by time.LoadLocation, so
+// the text format can be decoded.
+func ianaZoneName(name string) bool {
+	if ok, found := ianaZones.Load(name); found {
+		return ok.(bool)
+	}
+	_, err := time.LoadLocation(name)
+	ianaZones.Store(name, err == nil)
+	return err == nil
+}
+
+var localZone struct {
+	once sync.Once
+	name string
+}
+
+// localZoneName returns the IANA name of time.Local location, from TZ
+// environment variable or /etc/localtime link, or empty if it is unknown.
+func localZoneName() string {
+	localZone.once.Do(func() {
+		tz, ok := os.LookupEnv("TZ")
+		if !ok {
+			tz, _ = filepath.EvalSymlinks("/etc/localtime")
+		} else if tz == "" {
+			tz = "UTC"
+		}
+		tz = strings.TrimPrefix(tz, ":")
+		if i := strings.LastIndex(tz, "zoneinfo/"); i >= 0 {
+			tz = tz[i+len("zoneinfo/"):]
+		}
+		if tz == "" || tz == "Local" || strings.HasPrefix(tz, "/") {
+			return
+		}
+		if _, err := time.LoadLocation(tz); err == nil {
+			localZone.name = tz
+		}
+	})
+	return localZone.name
+}
+
+// Date returns the date with time zone offset at the start of day.
+func (z ZonedDate) Date() Date {
+	return MakeDate(z.tm)
+}
+
+// AddDate returns the date of adding years, months and days to z date in the
+// same location. Like time.AddDate, the result is normalized so 31 October
+// plus 1 month is 1 December. If the resulting date doesn't exist in the
+// location the next date is used.
+func (z ZonedDate) AddDate(years, months, days int) ZonedDate {
+	y, m, d := z.tm.Date()
+	t := time.Date(y+years, m+time.Month(months), d+days, 12, 0, 0, 0,
+		time.UTC)
+	for {
+		y, m, d = t.Date()
+		if tm, ok := startOfDay(y, m, d, z.tm.Location()); ok {
+			return ZonedDate{tm: tm}
+		}
+		t = t.AddDate(0, 0, 1)
+	}
+}
+
+// String returns date formatted like ``2021-02-01[Asia/Jakarta]''.
+func (z ZonedDate) String() string {
+	return z.tm.Format("2006-01-02") + "[" + z.Zone() + "]"
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// This is basically the String() output.
+func (z ZonedDate) MarshalText() ([]byte, error) {
+	return []byte(z.String()), nil
+}
+
+var zonedDateRE = regexp.MustCompile(`^(\d{4}-\d\d-\d\d)\[!?([^\[\]]+)\]$`)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The date is expected like String() format, RFC 9557 critical flag
+// ``[!Asia/Jakarta]'' is also accepted.
+func (z *ZonedDate) UnmarshalText(b []byte) error {
+	m := zonedDateRE.FindSubmatch(b)
+	if m == nil {
+		return fmt.Errorf("invalid text for %T: %q", z, b)
+	}
+
+	t, err := time.Parse("2006-01-02", string(m[1]))
+	if err != nil {
+		return fmt.Errorf("invalid date: %s", m[1])
+	}
+
+	var loc *time.Location
+	if c := m[2][0]; c == '+' || c == '-' {
+		tz, err := ParseTZ(string(m[2]))
+		if err != nil {
+			return err
+		}
+		loc = time.FixedZone("", int(tz)*60)
+	} else if loc, err = time.LoadLocation(string(m[2])); err != nil {
+		return fmt.Errorf("invalid timezone: %q", m[2])
+	}
+
+	y, mo, d := t.Date()
+	tm, ok := startOfDay(y, mo, d, loc)
+	if !ok {
+		return fmt.Errorf("invalid date: %s", b)
+	}
+	z.tm = tm
+	return nil
+}
@@ -0,0 +1,128 @@
+package util_test
+
+import (
+	"encoding/json"
+	. "github.com/hanindo/util/v2"
+	"time"
+	_ "time/tzdata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ZonedDate", func() {
+	load := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		Expect(err).To(Succeed())
+		return loc
+	}
+
+	DescribeTable("Valid",
+		func(zone string, t, str, start, end string) {
+			tm, _ := time.Parse(time.RFC3339, t)
+			z := MakeZonedDate(tm.In(load(zone)))
+			Expect(z.String()).To(Equal(str), "String")
+			Expect(z.Time().Format(time.RFC3339)).To(Equal(start), "Time")
+			Expect(z.End().Format(time.RFC3339)).To(Equal(end), "End")
+
+			b, err := json.Marshal(z)
+			Expect(err).To(Succeed(), "json encode err")
+			Expect(string(b)).To(Equal(`"`+str+`"`), "json encode")
+
+			var nz ZonedDate
+			Expect(json.Unmarshal(b, &nz)).To(Succeed(), "json decode err")
+			Expect(nz.Equal(z)).To(BeTrue(), "json decode")
+		},
+		Entry("Jakarta", "Asia/Jakarta", "2021-02-01T12:13:14+07:00",
+			"2021-02-01[Asia/Jakarta]",
+			"2021-02-01T00:00:00+07:00", "2021-02-02T00:00:00+07:00"),
+		Entry("Makassar", "Asia/Makassar", "2021-02-01T12:13:14+07:00",
+			"2021-02-01[Asia/Makassar]",
+			"2021-02-01T00:00:00+08:00", "2021-02-02T00:00:00+08:00"),
+		Entry("DST start at midnight", "America/Sao_Paulo",
+			"2018-11-04T12:13:14-02:00",
+			"2018-11-04[America/Sao_Paulo]",
+			"2018-11-04T01:00:00-02:00", "2018-11-05T00:00:00-02:00"),
+		Entry("DST before midnight", "America/Sao_Paulo",
+			"2018-11-03T12:13:14-03:00",
+			"2018-11-03[America/Sao_Paulo]",
+			"2018-11-03T00:00:00-03:00", "2018-11-04T01:00:00-02:00"),
+		Entry("DST end at midnight", "America/Sao_Paulo",
+			"2019-02-16T12:13:14-03:00",
+			"2019-02-16[America/Sao_Paulo]",
+			"2019-02-16T00:00:00-02:00", "2019-02-17T00:00:00-03:00"),
+		Entry("fixed zone", "UTC", "2021-02-01T12:13:14Z",
+			"2021-02-01[UTC]",
+			"2021-02-01T00:00:00Z", "2021-02-02T00:00:00Z"),
+	)
+
+	It("should use offset for unnamed location", func() {
+		t := time.Date(2021, time.February, 1, 12, 0, 0, 0,
+			time.FixedZone("", 345*60))
+		z := MakeZonedDate(t)
+		Expect(z.String()).To(Equal("2021-02-01[+05:45]"))
+		Expect(z.Date().String()).To(Equal("2021-02-01 +05:45"))
+
+		var nz ZonedDate
+		Expect(nz.UnmarshalText([]byte(z.String()))).To(Succeed())
+		Expect(nz.Equal(z)).To(BeTrue())
+	})
+
+	It("should use offset for abbreviated location", func() {
+		t := time.Date(2021, time.February, 1, 12, 0, 0, 0,
+			time.FixedZone("WIB", 7*3600))
+		z := MakeZonedDate(t)
+		Expect(z.Zone()).To(Equal("+07:00"))
+		b, err := z.MarshalText()
+		Expect(err).To(Succeed())
+		Expect(string(b)).To(Equal("2021-02-01[+07:00]"))
+
+		var nz ZonedDate
+		Expect(nz.UnmarshalText(b)).To(Succeed())
+		Expect(nz.Equal(z)).To(BeTrue())
+	})
+
+	It("should name local location", func() {
+		t := time.Date(2021, time.February, 1, 12, 0, 0, 0, time.Local)
+		z := MakeZonedDate(t)
+		Expect(z.Zone()).NotTo(Equal("Local"))
+		Expect(z.String()).NotTo(ContainSubstring("[Local]"))
+
+		var nz ZonedDate
+		Expect(nz.UnmarshalText([]byte(z.String()))).To(Succeed())
+		Expect(nz.Time().Equal(z.Time())).To(BeTrue())
+		Expect(nz.End().Equal(z.End())).To(BeTrue())
+	})
+
+	It("should skip nonexistent date", func() {
+		t := time.Date(2011, time.December, 29, 12, 0, 0, 0,
+			load("Pacific/Apia"))
+		z := MakeZonedDate(t).AddDate(0, 0, 1)
+		Expect(z.String()).To(Equal("2011-12-31[Pacific/Apia]"))
+	})
+
+	It("should accept critical flag", func() {
+		var z ZonedDate
+		Expect(z.UnmarshalText([]byte("2021-02-01[!Asia/Jakarta]"))).
+			To(Succeed())
+		Expect(z.String()).To(Equal("2021-02-01[Asia/Jakarta]"))
+	})
+
+	DescribeTable("UnmarshalText invalid",
+		func(err, s string) {
+			var z ZonedDate
+			Expect(z.UnmarshalText([]byte(s))).To(MatchError(err))
+		},
+		Entry("text", `invalid text for *util.ZonedDate: "2021-02-01"`,
+			"2021-02-01"),
+		Entry("date", "invalid date: 2021-02-29",
+			"2021-02-29[Asia/Jakarta]"),
+		Entry("zone", `invalid timezone: "Asia/Nowhere"`,
+			"2021-02-01[Asia/Nowhere]"),
+		Entry("offset", `invalid timezone: "+07:10"`,
+			"2021-02-01[+07:10]"),
+		Entry("nonexistent", "invalid date: 2011-12-30[Pacific/Apia]",
+			"2011-12-30[Pacific/Apia]"),
+	)
+})